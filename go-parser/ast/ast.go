@@ -40,6 +40,54 @@ func (a And) String() string {
 	return fmt.Sprintf("&(%v,%v)", a.LHS, a.RHS)
 }
 
+// Xor is the logical XOR operator in an AST. It's true if exactly one of its
+// operands is true.
+type Xor struct {
+	LHS Node
+	RHS Node
+}
+
+// Eval implements the Node interface.
+func (x Xor) Eval(vars map[string]bool) bool {
+	return x.LHS.Eval(vars) != x.RHS.Eval(vars)
+}
+
+func (x Xor) String() string {
+	return fmt.Sprintf("^(%v,%v)", x.LHS, x.RHS)
+}
+
+// Implies is the logical implication in an AST. It's false only if the LHS is
+// true and the RHS is false.
+type Implies struct {
+	LHS Node
+	RHS Node
+}
+
+// Eval implements the Node interface.
+func (i Implies) Eval(vars map[string]bool) bool {
+	return !i.LHS.Eval(vars) || i.RHS.Eval(vars)
+}
+
+func (i Implies) String() string {
+	return fmt.Sprintf("->(%v,%v)", i.LHS, i.RHS)
+}
+
+// Equiv is the logical equivalence in an AST. It's true if both of its
+// operands have the same value.
+type Equiv struct {
+	LHS Node
+	RHS Node
+}
+
+// Eval implements the Node interface.
+func (e Equiv) Eval(vars map[string]bool) bool {
+	return e.LHS.Eval(vars) == e.RHS.Eval(vars)
+}
+
+func (e Equiv) String() string {
+	return fmt.Sprintf("<->(%v,%v)", e.LHS, e.RHS)
+}
+
 // Not is the NOT operator in the AST.
 type Not struct {
 	Ex Node
@@ -67,3 +115,17 @@ func (v Val) Eval(vars map[string]bool) bool {
 func (v Val) String() string {
 	return fmt.Sprintf("'%v'", v.Name)
 }
+
+// Const is the boolean constant true or false in an AST.
+type Const struct {
+	Value bool
+}
+
+// Eval implements the Node interface.
+func (c Const) Eval(vars map[string]bool) bool {
+	return c.Value
+}
+
+func (c Const) String() string {
+	return fmt.Sprint(c.Value)
+}
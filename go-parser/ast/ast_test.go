@@ -1,6 +1,7 @@
 package ast
 
 import (
+  "fmt"
   "testing"
 )
 
@@ -34,3 +35,230 @@ func TestAST(t *testing.T) {
 		}
 	}
 }
+
+func TestOperators(t *testing.T) {
+
+	// ASTs for the expressions: "A XOR B", "A -> B", "A <-> B", "A AND true", "B OR false"
+	xor := Xor{Val{"A"}, Val{"B"}}
+	implies := Implies{Val{"A"}, Val{"B"}}
+	equiv := Equiv{Val{"A"}, Val{"B"}}
+	andTrue := And{Val{"A"}, Const{true}}
+	orFalse := Or{Val{"B"}, Const{false}}
+
+	// Table to test all combinations for A, B -> 2^2 = 4 combinations.
+	// Format of Table: { Value for A, Value for B, Expected Results for xor, implies, equiv, andTrue, orFalse }
+	truthTable := [][]bool{
+		{false, false, false, true, true, false, false},
+		{false, true, true, true, false, false, true},
+		{true, false, true, false, false, true, false},
+		{true, true, false, true, true, true, true},
+	}
+
+	// Test all possible combinations.
+	for _, tt := range truthTable {
+
+		vars := map[string]bool{"A": tt[0], "B": tt[1]}
+		for index, ast := range []Node{xor, implies, equiv, andTrue, orFalse} {
+			expected := tt[2+index]
+			result := ast.Eval(vars)
+
+			if result != expected {
+				t.Errorf("Expected %v but got %v. (Expression := %v, Vars := %v)", expected, result, ast, vars)
+			}
+		}
+	}
+
+	expected := "<->(->(^('A','B'),true),false)"
+	if result := fmt.Sprint(Equiv{Implies{xor, Const{true}}, Const{false}}); result != expected {
+		t.Errorf("Expected %v but got %v.", expected, result)
+	}
+}
+
+func TestStripSpans(t *testing.T) {
+	span := Span{Position{0, 1, 1}, Position{2, 1, 3}}
+	spanned := Spanned{Not{Spanned{Val{"A"}, span}}, span}
+	if spanned.Span() != span || spanned.String() != "!('A')" || spanned.Eval(map[string]bool{"A": false}) != true {
+		t.Errorf("Spanned must behave like the node it wraps but got %v with span %v", spanned, spanned.Span())
+	}
+	if stripped := StripSpans(spanned); stripped != (Not{Val{"A"}}) {
+		t.Errorf("Expected %v but got %v. (Expression := %#v)", Not{Val{"A"}}, stripped, spanned)
+	}
+}
+
+func TestEvalKleene(t *testing.T) {
+
+	// AST for expression: "A AND B OR !C"
+	ast := Or{And{Val{"A"}, Val{"B"}}, Not{Val{"C"}}}
+
+	// Table to test combinations of A, B, C that leave some of them unset.
+	// Format of Table: { Value for A, Value for B, Value for C, Expected Result }
+	truthTable := [][]Truth{
+		{Unknown, Unknown, Unknown, Unknown},
+		{Unknown, Unknown, False, True},
+		{Unknown, False, True, False},
+		{Unknown, True, True, Unknown},
+		{True, True, Unknown, True},
+		{False, Unknown, Unknown, Unknown},
+		{False, Unknown, True, False},
+		{True, False, False, True},
+	}
+
+	for _, tt := range truthTable {
+
+		vars := map[string]bool{}
+		for index, name := range []string{"A", "B", "C"} {
+			if tt[index] != Unknown {
+				vars[name] = tt[index] == True
+			}
+		}
+		expected := tt[3]
+		result := EvalKleene(ast, vars)
+
+		if result != expected {
+			t.Errorf("Expected %v but got %v. (Expression := %v, Vars := %v)", expected, result, ast, vars)
+		}
+	}
+
+	// Format of Table: { Value for A, Value for B, Expected Results for A XOR B, A -> B, A <-> B }
+	operatorTable := [][]Truth{
+		{Unknown, Unknown, Unknown, Unknown, Unknown},
+		{False, Unknown, Unknown, True, Unknown},
+		{Unknown, True, Unknown, True, Unknown},
+		{True, Unknown, Unknown, Unknown, Unknown},
+		{True, False, True, False, False},
+	}
+	for _, tt := range operatorTable {
+		vars := map[string]bool{}
+		for index, name := range []string{"A", "B"} {
+			if tt[index] != Unknown {
+				vars[name] = tt[index] == True
+			}
+		}
+		for index, ast := range []Node{Xor{Val{"A"}, Val{"B"}}, Implies{Val{"A"}, Val{"B"}}, Equiv{Val{"A"}, Val{"B"}}} {
+			if result := EvalKleene(ast, vars); result != tt[2+index] {
+				t.Errorf("Expected %v but got %v. (Expression := %v, Vars := %v)", tt[2+index], result, ast, vars)
+			}
+		}
+	}
+}
+
+func TestEvalStrict(t *testing.T) {
+	ast := Spanned{Or{And{Val{"B"}, Const{true}}, Not{Or{Val{"C"}, Val{"A"}}}}, Span{}}
+
+	result, err := EvalStrict(ast, map[string]bool{"A": false, "B": true, "C": false})
+	if err != nil || result != true {
+		t.Errorf("Expected true but got %v with error %v. (Expression := %v)", result, err, ast)
+	}
+
+	_, err = EvalStrict(ast, map[string]bool{"B": true})
+	expected := "unbound variables: A, C"
+	if unbound, isUnbound := err.(*UnboundError); !isUnbound || unbound.Error() != expected {
+		t.Errorf("Expected error %q but got %v. (Expression := %v)", expected, err, ast)
+	}
+}
+
+func TestExplain(t *testing.T) {
+
+	// AST for expression: "A AND B OR !C"
+	ast := Or{And{Val{"A"}, Val{"B"}}, Not{Val{"C"}}}
+
+	explanation := Explain(ast, map[string]bool{"A": true, "C": true})
+	expected := "| false\n" +
+		"  & false\n" +
+		"    'A' true\n" +
+		"    'B' false, not set\n" +
+		"  ! false\n" +
+		"    'C' true"
+	if explanation.String() != expected {
+		t.Errorf("Expected\n%v\nbut got\n%v", expected, explanation)
+	}
+
+	explanation = Explain(Spanned{ast, Span{}}, map[string]bool{"A": false, "C": false})
+	expected = "| true\n" +
+		"  & false\n" +
+		"    'A' false\n" +
+		"    'B' skipped\n" +
+		"  ! true\n" +
+		"    'C' false"
+	if explanation.String() != expected || explanation.Node != (Spanned{ast, Span{}}) {
+		t.Errorf("Expected\n%v\nbut got\n%v", expected, explanation)
+	}
+
+	explanation = Explain(Or{Const{true}, ast}, nil)
+	if !explanation.Value || !explanation.Operands[1].Skipped || explanation.Operands[1].Node != ast {
+		t.Errorf("Expected the RHS of %v to be skipped but got\n%v", Or{Const{true}, ast}, explanation)
+	}
+
+	// Table to check the values of all operators against Eval.
+	for _, ast := range []Node{Xor{Val{"A"}, Val{"B"}}, Implies{Val{"A"}, Val{"B"}}, Equiv{Val{"A"}, Val{"B"}}} {
+		for _, tt := range [][]bool{{false, false}, {false, true}, {true, false}, {true, true}} {
+			vars := map[string]bool{"A": tt[0], "B": tt[1]}
+			if result := Explain(ast, vars).Value; result != ast.Eval(vars) {
+				t.Errorf("Expected %v but got %v. (Expression := %v, Vars := %v)", ast.Eval(vars), result, ast, vars)
+			}
+		}
+	}
+}
+
+// countingVisitor counts the nodes it visits and the calls with nil.
+type countingVisitor struct {
+	nodes []string
+	nils  int
+}
+
+func (v *countingVisitor) Visit(node Node) Visitor {
+	if node == nil {
+		v.nils++
+		return nil
+	}
+	v.nodes = append(v.nodes, Label(node))
+	return v
+}
+
+func TestWalk(t *testing.T) {
+
+	// AST for expression: "(A -> B) <-> !C ^ true"
+	ast := Equiv{Implies{Val{"A"}, Val{"B"}}, Xor{Not{Val{"C"}}, Const{true}}}
+
+	v := &countingVisitor{}
+	Walk(v, ast)
+	expected := "[<-> -> 'A' 'B' ^ ! 'C' true]"
+	if result := fmt.Sprint(v.nodes); result != expected || v.nils != 8 {
+		t.Errorf("Expected %v and 8 calls with nil but got %v and %v. (Expression := %v)", expected, result, v.nils, ast)
+	}
+
+	visited := 0
+	Inspect(ast, func(node Node) bool {
+		if node != nil {
+			visited++
+		}
+		_, isXor := node.(Xor)
+		return !isXor
+	})
+	if visited != 5 {
+		t.Errorf("Expected Inspect to visit 5 nodes but got %v. (Expression := %v)", visited, ast)
+	}
+}
+
+func TestRewriteAndVars(t *testing.T) {
+	ast := Or{And{Val{"b"}, Val{"a"}}, Not{Spanned{Val{"c"}, Span{}}}}
+
+	rewritten := Rewrite(ast, func(node Node) Node {
+		if val, isVal := node.(Val); isVal && val.Name == "a" {
+			return Const{true}
+		}
+		return node
+	})
+	expected := Or{And{Val{"b"}, Const{true}}, Not{Spanned{Val{"c"}, Span{}}}}
+	if rewritten != expected || ast.LHS != (And{Val{"b"}, Val{"a"}}) {
+		t.Errorf("Expected %v but got %v. (Expression := %v)", expected, rewritten, ast)
+	}
+
+	vars := fmt.Sprint(Vars(And{ast, Val{"a"}}))
+	if vars != "[a b c]" {
+		t.Errorf("Expected [a b c] but got %v. (Expression := %v)", vars, ast)
+	}
+	if vars := Vars(Const{false}); vars == nil || len(vars) != 0 {
+		t.Errorf("Expected [] but got %#v.", vars)
+	}
+}
@@ -0,0 +1,132 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explanation tells how the value of a node came about when it was evaluated.
+// Explain creates it along with the Explanations of all the sub-nodes.
+type Explanation struct {
+
+	// Node is the node that was evaluated.
+	Node Node
+
+	// Value is the value of the node. It's false if the node was Skipped.
+	Value bool
+
+	// Skipped is true if the node wasn't evaluated at all, because its
+	// sibling already decided the value of the parent, e.g. the RHS of an Or
+	// whose LHS is true. This is known as short-circuit evaluation.
+	Skipped bool
+
+	// Unbound is true if the node is a variable that isn't set and was thus
+	// evaluated to false.
+	Unbound bool
+
+	// Operands are the Explanations of the sub-nodes in the order of the
+	// operands. Skipped nodes have no Operands.
+	Operands []*Explanation
+}
+
+// Explain evaluates the AST like Eval and returns an Explanation for every
+// node, i. e. it answers the question why the expression is true or false.
+// Or, And and Implies skip their RHS if the LHS decides the value.
+// Spanned nodes are explained like the node they wrap, but the Explanation
+// keeps the Spanned node, so that its Span is available.
+func Explain(node Node, vars map[string]bool) *Explanation {
+	switch n := node.(type) {
+	case Spanned:
+		var explanation = Explain(n.Ex, vars)
+		explanation.Node = node
+		return explanation
+	case Or:
+		return explainShortCircuit(node, n.LHS, n.RHS, vars, true, true)
+	case And:
+		return explainShortCircuit(node, n.LHS, n.RHS, vars, false, false)
+	case Implies:
+		return explainShortCircuit(node, n.LHS, n.RHS, vars, false, true)
+	case Xor:
+		var lhs, rhs = Explain(n.LHS, vars), Explain(n.RHS, vars)
+		return &Explanation{Node: node, Value: lhs.Value != rhs.Value, Operands: []*Explanation{lhs, rhs}}
+	case Equiv:
+		var lhs, rhs = Explain(n.LHS, vars), Explain(n.RHS, vars)
+		return &Explanation{Node: node, Value: lhs.Value == rhs.Value, Operands: []*Explanation{lhs, rhs}}
+	case Not:
+		var operand = Explain(n.Ex, vars)
+		return &Explanation{Node: node, Value: !operand.Value, Operands: []*Explanation{operand}}
+	case Val:
+		var value, found = vars[n.Name]
+		return &Explanation{Node: node, Value: value, Unbound: !found}
+	}
+	return &Explanation{Node: node, Value: node.Eval(vars)}
+}
+
+// explainShortCircuit explains an operator that skips its rhs if its lhs has
+// the decisive value. In that case the value of the operator is result.
+// Otherwise the value of the operator is the value of the rhs.
+func explainShortCircuit(node Node, lhs Node, rhs Node, vars map[string]bool,
+	decisive bool, result bool) *Explanation {
+	var left = Explain(lhs, vars)
+	if left.Value == decisive {
+		return &Explanation{Node: node, Value: result,
+			Operands: []*Explanation{left, {Node: rhs, Skipped: true}}}
+	}
+	var right = Explain(rhs, vars)
+	return &Explanation{Node: node, Value: right.Value, Operands: []*Explanation{left, right}}
+}
+
+// String renders the Explanation as an indented tree with one line per node.
+// Skipped nodes are rendered as a whole. E.g. for "a & b | !c" with a = true
+// and c = true:
+//
+//	| false
+//	  & false
+//	    'a' true
+//	    'b' false, not set
+//	  ! false
+//	    'c' true
+func (explanation *Explanation) String() string {
+	var builder strings.Builder
+	explanation.render(&builder, 0)
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// render writes the lines of the Explanation indented by depth levels.
+func (explanation *Explanation) render(builder *strings.Builder, depth int) {
+	builder.WriteString(strings.Repeat("  ", depth))
+	switch {
+	case explanation.Skipped:
+		fmt.Fprintf(builder, "%v skipped", explanation.Node)
+	case explanation.Unbound:
+		fmt.Fprintf(builder, "%v false, not set", explanation.Node)
+	default:
+		fmt.Fprintf(builder, "%s %v", Label(explanation.Node), explanation.Value)
+	}
+	builder.WriteString("\n")
+	for _, operand := range explanation.Operands {
+		operand.render(builder, depth+1)
+	}
+}
+
+// Label returns the operator of a node or the node itself if it has no
+// operands.
+func Label(node Node) string {
+	switch node := node.(type) {
+	case Spanned:
+		return Label(node.Ex)
+	case Or:
+		return "|"
+	case And:
+		return "&"
+	case Xor:
+		return "^"
+	case Implies:
+		return "->"
+	case Equiv:
+		return "<->"
+	case Not:
+		return "!"
+	}
+	return fmt.Sprint(node)
+}
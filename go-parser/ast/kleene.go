@@ -0,0 +1,139 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Truth is the value of an expression in Kleene's three-valued logic: an
+// expression is True, False or Unknown if it depends on variables that aren't
+// set. The values are ordered False < Unknown < True, so that And is the
+// minimum and Or is the maximum of the operands.
+type Truth int
+
+const (
+
+	// False is the Truth of an expression that is false.
+	False Truth = iota
+
+	// Unknown is the Truth of an expression that may be true or false
+	// depending on the variables that aren't set.
+	Unknown
+
+	// True is the Truth of an expression that is true.
+	True
+)
+
+// TruthOf converts a bool to a Truth.
+func TruthOf(value bool) Truth {
+	if value {
+		return True
+	}
+	return False
+}
+
+// Not returns the negation of t. The negation of Unknown is Unknown.
+func (t Truth) Not() Truth {
+	return True - t
+}
+
+// And returns the conjunction of t and u, i. e. the smaller one of them.
+func (t Truth) And(u Truth) Truth {
+	if u < t {
+		return u
+	}
+	return t
+}
+
+// Or returns the disjunction of t and u, i. e. the larger one of them.
+func (t Truth) Or(u Truth) Truth {
+	if u > t {
+		return u
+	}
+	return t
+}
+
+// Xor returns true if exactly one of t and u is true.
+func (t Truth) Xor(u Truth) Truth {
+	return t.And(u.Not()).Or(t.Not().And(u))
+}
+
+// Implies returns the implication from t to u.
+func (t Truth) Implies(u Truth) Truth {
+	return t.Not().Or(u)
+}
+
+// Equiv returns true if t and u have the same value.
+func (t Truth) Equiv(u Truth) Truth {
+	return t.Xor(u).Not()
+}
+
+func (t Truth) String() string {
+	switch t {
+	case False:
+		return "false"
+	case True:
+		return "true"
+	}
+	return "unknown"
+}
+
+// EvalKleene evaluates the AST in Kleene's three-valued logic. Missing vars
+// are Unknown, so the result is Unknown if it depends on them. Nodes of other
+// packages can't be Unknown, they are evaluated by their Eval method.
+func EvalKleene(node Node, vars map[string]bool) Truth {
+	switch node := node.(type) {
+	case Or:
+		return EvalKleene(node.LHS, vars).Or(EvalKleene(node.RHS, vars))
+	case And:
+		return EvalKleene(node.LHS, vars).And(EvalKleene(node.RHS, vars))
+	case Xor:
+		return EvalKleene(node.LHS, vars).Xor(EvalKleene(node.RHS, vars))
+	case Implies:
+		return EvalKleene(node.LHS, vars).Implies(EvalKleene(node.RHS, vars))
+	case Equiv:
+		return EvalKleene(node.LHS, vars).Equiv(EvalKleene(node.RHS, vars))
+	case Not:
+		return EvalKleene(node.Ex, vars).Not()
+	case Val:
+		var value, found = vars[node.Name]
+		if !found {
+			return Unknown
+		}
+		return TruthOf(value)
+	case Const:
+		return TruthOf(node.Value)
+	case Spanned:
+		return EvalKleene(node.Ex, vars)
+	}
+	return TruthOf(node.Eval(vars))
+}
+
+// UnboundError is the error of EvalStrict if variables of the expression
+// aren't set.
+type UnboundError struct {
+
+	// Names are the names of the variables that aren't set in sorted order.
+	Names []string
+}
+
+// Error implements the error interface.
+func (err *UnboundError) Error() string {
+	return fmt.Sprintf("unbound variables: %s", strings.Join(err.Names, ", "))
+}
+
+// EvalStrict evaluates the AST like Eval but doesn't treat missing variables
+// as false: if any variable of the expression is missing in the vars map, the
+// result is an *UnboundError listing all of the missing variables.
+func EvalStrict(node Node, vars map[string]bool) (bool, error) {
+	var unbound []string
+	for _, name := range Vars(node) {
+		if _, found := vars[name]; !found {
+			unbound = append(unbound, name)
+		}
+	}
+	if len(unbound) > 0 {
+		return false, &UnboundError{unbound}
+	}
+	return node.Eval(vars), nil
+}
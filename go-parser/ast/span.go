@@ -0,0 +1,62 @@
+package ast
+
+import "fmt"
+
+// Position is a location in the source text of an expression.
+type Position struct {
+
+	// Offset is the number of code points that precede the location.
+	Offset int
+
+	// Line is the line of the location. The first line is 1.
+	Line int
+
+	// Column is the code point in the Line. The first column is 1.
+	Column int
+}
+
+// Span is the part of the source text that a node was parsed from. Start is
+// the location of the first code point of the node, End the location right
+// after its last code point.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// Spanner is implemented by nodes that know their Span, i. e. by Spanned.
+type Spanner interface {
+	Span() Span
+}
+
+// Spanned attaches the Span of its source text to a node. A parser that records
+// spans wraps every node in a Spanned, e.g. boolparser.ParseWithSpans.
+// Spanned behaves exactly like the node it wraps. Use StripSpans to get rid of
+// the Spanned nodes, e.g. to compare trees.
+type Spanned struct {
+	Ex       Node
+	Location Span
+}
+
+// Eval implements the Node interface.
+func (s Spanned) Eval(vars map[string]bool) bool {
+	return s.Ex.Eval(vars)
+}
+
+// Span implements the Spanner interface.
+func (s Spanned) Span() Span {
+	return s.Location
+}
+
+func (s Spanned) String() string {
+	return fmt.Sprint(s.Ex)
+}
+
+// StripSpans returns the tree without any Spanned nodes.
+func StripSpans(node Node) Node {
+	return Rewrite(node, func(node Node) Node {
+		if spanned, isSpanned := node.(Spanned); isSpanned {
+			return spanned.Ex
+		}
+		return node
+	})
+}
@@ -0,0 +1,118 @@
+package ast
+
+import "sort"
+
+// Visitor is called by Walk for every node of an AST. Visit returns the
+// Visitor for the operands of the node or nil to skip them.
+type Visitor interface {
+	Visit(node Node) Visitor
+}
+
+// Walk visits the node and then walks its operands from left to right with
+// the Visitor that Visit returned. After the operands it calls Visit(nil)
+// on that Visitor. A Spanned is a node of its own whose operand is the node
+// it wraps, so the wrapped node is visited after the Spanned.
+func Walk(visitor Visitor, node Node) {
+	if visitor = visitor.Visit(node); visitor == nil {
+		return
+	}
+	for _, operand := range Operands(node) {
+		Walk(visitor, operand)
+	}
+	visitor.Visit(nil)
+}
+
+// inspector is the Visitor of Inspect.
+type inspector func(Node) bool
+
+// Visit implements the Visitor interface.
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect is Walk with a function: it calls f for the node and, if f returns
+// true, inspects the operands and calls f(nil) after them.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// Operands returns the sub-nodes of the node from left to right, e.g. the LHS
+// and the RHS of an Or. Variables and constants have no operands. The
+// operand of a Spanned is the node it wraps.
+func Operands(node Node) []Node {
+	switch node := node.(type) {
+	case Spanned:
+		return []Node{node.Ex}
+	case Or:
+		return []Node{node.LHS, node.RHS}
+	case And:
+		return []Node{node.LHS, node.RHS}
+	case Xor:
+		return []Node{node.LHS, node.RHS}
+	case Implies:
+		return []Node{node.LHS, node.RHS}
+	case Equiv:
+		return []Node{node.LHS, node.RHS}
+	case Not:
+		return []Node{node.Ex}
+	}
+	return nil
+}
+
+// withOperands returns a copy of the node with the operands replaced. The
+// operands have to be in the order of Operands.
+func withOperands(node Node, operands []Node) Node {
+	switch node := node.(type) {
+	case Spanned:
+		return Spanned{operands[0], node.Location}
+	case Or:
+		return Or{operands[0], operands[1]}
+	case And:
+		return And{operands[0], operands[1]}
+	case Xor:
+		return Xor{operands[0], operands[1]}
+	case Implies:
+		return Implies{operands[0], operands[1]}
+	case Equiv:
+		return Equiv{operands[0], operands[1]}
+	case Not:
+		return Not{operands[0]}
+	}
+	return node
+}
+
+// Rewrite transforms an AST bottom-up: it rewrites the operands of the node
+// first and then calls f with a copy of the node that holds the rewritten
+// operands. The result of f replaces the node in the new tree. Rewrite
+// doesn't change the original tree. E.g. StripSpans is a Rewrite that
+// replaces every Spanned with the node it wraps.
+func Rewrite(node Node, f func(Node) Node) Node {
+	var operands = Operands(node)
+	if len(operands) > 0 {
+		var rewritten = make([]Node, len(operands))
+		for index, operand := range operands {
+			rewritten[index] = Rewrite(operand, f)
+		}
+		node = withOperands(node, rewritten)
+	}
+	return f(node)
+}
+
+// Vars returns the names of the variables in the AST in sorted order. Every
+// name appears once, even if the variable appears several times.
+func Vars(node Node) []string {
+	var found = make(map[string]bool)
+	var names = []string{}
+	Inspect(node, func(node Node) bool {
+		if val, isVal := node.(Val); isVal && !found[val.Name] {
+			found[val.Name] = true
+			names = append(names, val.Name)
+		}
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,347 @@
+package bdd
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+)
+
+// BDD is a reduced ordered binary decision diagram created by a Manager. A
+// BDD is a decision tree on the variables of an expression: every node tests
+// a variable and continues with its low child if the variable is false and
+// with its high child otherwise. The variables are tested in the order of the
+// Manager and equal sub-trees are shared, which makes the BDD canonical: two
+// expressions are equivalent if and only if they result in the same BDD of
+// the same Manager.
+type BDD int
+
+const (
+
+	// False is the BDD that is always false.
+	False BDD = 0
+
+	// True is the BDD that is always true.
+	True BDD = 1
+)
+
+// node is a node of a BDD.
+type node struct {
+	level int
+	low   BDD
+	high  BDD
+}
+
+// operator is a binary operator for apply.
+type operator int
+
+const (
+	and operator = iota
+	or
+	xor
+	implies
+	equiv
+)
+
+// applyKey identifies a cached result of apply.
+type applyKey struct {
+	op   operator
+	a, b BDD
+}
+
+// Manager creates and holds BDDs that share nodes and a variable order.
+// BDDs of different Managers can't be combined. A Manager isn't safe for
+// concurrent use.
+type Manager struct {
+
+	// vars are the names of the variables by level. Level 0 is tested first.
+	vars []string
+
+	// levels are the levels of the variables by name.
+	levels map[string]int
+
+	// nodes are the nodes by BDD. The first two are placeholders for False
+	// and True.
+	nodes []node
+
+	// unique makes sure that every node exists only once.
+	unique map[node]BDD
+
+	// cache holds the results of apply.
+	cache map[applyKey]BDD
+}
+
+// NewManager creates a Manager that tests the variables in the order. The
+// order has a huge impact on the size of the BDDs, see OrderByAppearance and
+// OrderByFrequency. Variables that aren't in the order are tested last in the
+// order in which they're first used.
+func NewManager(order []string) *Manager {
+	var m = &Manager{
+		levels: make(map[string]int),
+		nodes:  []node{{level: math.MaxInt}, {level: math.MaxInt}},
+		unique: make(map[node]BDD),
+		cache:  make(map[applyKey]BDD),
+	}
+	for _, name := range order {
+		m.level(name)
+	}
+	return m
+}
+
+// level returns the level of the variable, which is added to the order if
+// it's new.
+func (m *Manager) level(name string) int {
+	var level, found = m.levels[name]
+	if !found {
+		level = len(m.vars)
+		m.vars = append(m.vars, name)
+		m.levels[name] = level
+	}
+	return level
+}
+
+// Vars returns the variables of the Manager in their order.
+func (m *Manager) Vars() []string {
+	return append([]string{}, m.vars...)
+}
+
+// make returns the node with the children, which is low if both children are
+// equal.
+func (m *Manager) make(level int, low BDD, high BDD) BDD {
+	if low == high {
+		return low
+	}
+	var n = node{level, low, high}
+	if b, found := m.unique[n]; found {
+		return b
+	}
+	m.nodes = append(m.nodes, n)
+	m.unique[n] = BDD(len(m.nodes) - 1)
+	return BDD(len(m.nodes) - 1)
+}
+
+// Var returns the BDD that is true if the variable is true.
+func (m *Manager) Var(name string) BDD {
+	return m.make(m.level(name), False, True)
+}
+
+// Not returns the negation of the BDD.
+func (m *Manager) Not(a BDD) BDD {
+	return m.apply(xor, a, True)
+}
+
+// And returns the conjunction of the BDDs.
+func (m *Manager) And(a BDD, b BDD) BDD {
+	return m.apply(and, a, b)
+}
+
+// Or returns the disjunction of the BDDs.
+func (m *Manager) Or(a BDD, b BDD) BDD {
+	return m.apply(or, a, b)
+}
+
+// Xor returns the BDD that is true if exactly one of the BDDs is true.
+func (m *Manager) Xor(a BDD, b BDD) BDD {
+	return m.apply(xor, a, b)
+}
+
+// Implies returns the implication from a to b.
+func (m *Manager) Implies(a BDD, b BDD) BDD {
+	return m.apply(implies, a, b)
+}
+
+// Equiv returns the BDD that is true if both BDDs have the same value.
+func (m *Manager) Equiv(a BDD, b BDD) BDD {
+	return m.apply(equiv, a, b)
+}
+
+// evaluate applies the operator to two values.
+func (op operator) evaluate(a bool, b bool) bool {
+	switch op {
+	case and:
+		return a && b
+	case or:
+		return a || b
+	case xor:
+		return a != b
+	case implies:
+		return !a || b
+	}
+	return a == b
+}
+
+// apply combines two BDDs with the operator. It follows both BDDs in parallel
+// and caches the results, so that it takes time proportional to the product
+// of their sizes at most.
+func (m *Manager) apply(op operator, a BDD, b BDD) BDD {
+	if a <= True && b <= True {
+		if op.evaluate(a == True, b == True) {
+			return True
+		}
+		return False
+	}
+	switch {
+	case op == and && (a == False || b == False):
+		return False
+	case op == or && (a == True || b == True):
+		return True
+	}
+	var key = applyKey{op, a, b}
+	if result, found := m.cache[key]; found {
+		return result
+	}
+	var nodeA, nodeB = m.nodes[a], m.nodes[b]
+	var level = nodeA.level
+	if nodeB.level < level {
+		level = nodeB.level
+	}
+	var lowA, highA = m.cofactors(a, level)
+	var lowB, highB = m.cofactors(b, level)
+	var result = m.make(level, m.apply(op, lowA, lowB), m.apply(op, highA, highB))
+	m.cache[key] = result
+	return result
+}
+
+// cofactors returns the BDDs for the variable at the level being false and
+// true.
+func (m *Manager) cofactors(b BDD, level int) (BDD, BDD) {
+	var n = m.nodes[b]
+	if n.level != level {
+		return b, b
+	}
+	return n.low, n.high
+}
+
+// FromAST converts the expression to a BDD.
+func (m *Manager) FromAST(expression ast.Node) BDD {
+	switch expression := expression.(type) {
+	case ast.Spanned:
+		return m.FromAST(expression.Ex)
+	case ast.Val:
+		return m.Var(expression.Name)
+	case ast.Const:
+		if expression.Value {
+			return True
+		}
+		return False
+	case ast.Not:
+		return m.Not(m.FromAST(expression.Ex))
+	case ast.And:
+		return m.And(m.FromAST(expression.LHS), m.FromAST(expression.RHS))
+	case ast.Or:
+		return m.Or(m.FromAST(expression.LHS), m.FromAST(expression.RHS))
+	case ast.Xor:
+		return m.Xor(m.FromAST(expression.LHS), m.FromAST(expression.RHS))
+	case ast.Implies:
+		return m.Implies(m.FromAST(expression.LHS), m.FromAST(expression.RHS))
+	case ast.Equiv:
+		return m.Equiv(m.FromAST(expression.LHS), m.FromAST(expression.RHS))
+	}
+	panic(fmt.Sprintf("bdd: unknown node %v", expression))
+}
+
+// ToAST converts the BDD to an expression. Every node becomes an if-then-else
+// on its variable, i. e. (v & high) | (!v & low), which is simplified if a
+// child is True or False. The expression doesn't share sub-expressions, so it
+// may be a lot larger than the BDD.
+func (m *Manager) ToAST(b BDD) ast.Node {
+	switch b {
+	case False:
+		return ast.Const{Value: false}
+	case True:
+		return ast.Const{Value: true}
+	}
+	var n = m.nodes[b]
+	var v ast.Node = ast.Val{Name: m.vars[n.level]}
+	var notV ast.Node = ast.Not{Ex: v}
+	switch {
+	case n.low == False && n.high == True:
+		return v
+	case n.low == True && n.high == False:
+		return notV
+	case n.high == True:
+		return ast.Or{LHS: v, RHS: m.ToAST(n.low)}
+	case n.low == False:
+		return ast.And{LHS: v, RHS: m.ToAST(n.high)}
+	case n.high == False:
+		return ast.And{LHS: notV, RHS: m.ToAST(n.low)}
+	case n.low == True:
+		return ast.Or{LHS: notV, RHS: m.ToAST(n.high)}
+	}
+	return ast.Or{
+		LHS: ast.And{LHS: v, RHS: m.ToAST(n.high)},
+		RHS: ast.And{LHS: notV, RHS: m.ToAST(n.low)},
+	}
+}
+
+// Eval evaluates the BDD by following a single path from the root to True
+// or False. Missing vars are evaluated to false.
+func (m *Manager) Eval(b BDD, vars map[string]bool) bool {
+	for b > True {
+		var n = m.nodes[b]
+		if vars[m.vars[n.level]] {
+			b = n.high
+		} else {
+			b = n.low
+		}
+	}
+	return b == True
+}
+
+// Count returns the number of combinations of the values of all the variables
+// of the Manager that make the BDD true.
+func (m *Manager) Count(b BDD) *big.Int {
+	var counts = make(map[BDD]*big.Int)
+	var count = m.count(b, counts)
+	// count counts the combinations of the variables from the level of b on.
+	return count.Lsh(count, uint(m.levelOf(b)))
+}
+
+// count returns the number of combinations of the values of the variables
+// from the level of b on that make b true.
+func (m *Manager) count(b BDD, counts map[BDD]*big.Int) *big.Int {
+	switch b {
+	case False:
+		return big.NewInt(0)
+	case True:
+		return big.NewInt(1)
+	}
+	if count, found := counts[b]; found {
+		return new(big.Int).Set(count)
+	}
+	var n = m.nodes[b]
+	var low, high = m.count(n.low, counts), m.count(n.high, counts)
+	low.Lsh(low, uint(m.levelOf(n.low)-n.level-1))
+	high.Lsh(high, uint(m.levelOf(n.high)-n.level-1))
+	var count = low.Add(low, high)
+	counts[b] = new(big.Int).Set(count)
+	return count
+}
+
+// levelOf returns the level of the BDD, which is the number of variables for
+// True and False.
+func (m *Manager) levelOf(b BDD) int {
+	if b <= True {
+		return len(m.vars)
+	}
+	return m.nodes[b].level
+}
+
+// Size returns the number of nodes of the BDD including True and False.
+func (m *Manager) Size(b BDD) int {
+	var visited = make(map[BDD]bool)
+	var visit func(BDD)
+	visit = func(b BDD) {
+		if visited[b] {
+			return
+		}
+		visited[b] = true
+		if b > True {
+			visit(m.nodes[b].low)
+			visit(m.nodes[b].high)
+		}
+	}
+	visit(b)
+	return len(visited)
+}
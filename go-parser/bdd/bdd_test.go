@@ -0,0 +1,109 @@
+package bdd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/internal/asttest"
+)
+
+func TestCanonical(t *testing.T) {
+	var m = NewManager(nil)
+	var equivalent = [][]string{
+		{"a & b | a & c", "a & (b | c)"},
+		{"!(a & b)", "!a | !b"},
+		{"a -> b -> c", "a & b -> c"},
+		{"a ^ b", "!(a <-> b)"},
+		{"a | !a", "true"},
+		{"a & !a & b", "false"},
+	}
+	for _, pair := range equivalent {
+		var a, b = m.FromAST(boolparser.MustParse(pair[0])), m.FromAST(boolparser.MustParse(pair[1]))
+		if a != b {
+			t.Errorf("FromAST on input \"%v\" and \"%v\" must result in the same BDD but got %v and %v !",
+				pair[0], pair[1], a, b)
+		}
+	}
+	if m.FromAST(boolparser.MustParse("a -> b")) == m.FromAST(boolparser.MustParse("b -> a")) {
+		t.Errorf("FromAST on input \"a -> b\" and \"b -> a\" mustn't result in the same BDD!")
+	}
+}
+
+func TestEvalAndToAST(t *testing.T) {
+	for _, text := range []string{"a", "!a", "a & !b | c", "(a ^ b ^ c) -> d", "a <-> (b | !c & d)"} {
+		var node = boolparser.MustParse(text)
+		var m = NewManager(OrderByAppearance(node))
+		var b = m.FromAST(node)
+		var back = m.ToAST(b)
+		for _, vars := range asttest.Assignments(ast.Vars(node)) {
+			if m.Eval(b, vars) != node.Eval(vars) || back.Eval(vars) != node.Eval(vars) {
+				t.Errorf("BDD on input \"%v\" failed for %v! Expected %v but got %v and %v from %v !",
+					text, vars, node.Eval(vars), m.Eval(b, vars), back.Eval(vars), back)
+			}
+		}
+	}
+	var m = NewManager([]string{"a", "b"})
+	if result := fmt.Sprint(m.ToAST(m.FromAST(boolparser.MustParse("b & a")))); result != "&('a','b')" {
+		t.Errorf("ToAST on input \"b & a\" failed! Expected &('a','b') but got %v !", result)
+	}
+}
+
+func TestCount(t *testing.T) {
+	var m = NewManager([]string{"a", "b", "c", "d"})
+	var counts = map[string]int64{"a": 8, "a & b": 4, "a | b": 12, "a ^ d": 8, "true": 16,
+		"false": 0, "!(b & c & d)": 14, "a & !a": 0}
+	for text, expected := range counts {
+		if count := m.Count(m.FromAST(boolparser.MustParse(text))); count.Int64() != expected {
+			t.Errorf("Count on input \"%v\" failed! Expected %v but got %v !", text, expected, count)
+		}
+	}
+}
+
+func TestOrder(t *testing.T) {
+	var node = boolparser.MustParse("c & (b | a) | a & d")
+	if order := fmt.Sprint(OrderByAppearance(node)); order != "[c b a d]" {
+		t.Errorf("OrderByAppearance failed! Expected [c b a d] but got %v !", order)
+	}
+	if order := fmt.Sprint(OrderByFrequency(node)); order != "[a c b d]" {
+		t.Errorf("OrderByFrequency failed! Expected [a c b d] but got %v !", order)
+	}
+
+	// The size of a1 & b1 | a2 & b2 | ... grows exponentially if all the
+	// a's are tested before the b's and linearly if the pairs stay together.
+	var parts []string
+	for index := 0; index < 8; index++ {
+		parts = append(parts, fmt.Sprintf("a%d & b%d", index, index))
+	}
+	node = boolparser.MustParse(strings.Join(parts, " | "))
+	var good = NewManager(OrderByAppearance(node))
+	var bad = NewManager(strings.Fields("a0 a1 a2 a3 a4 a5 a6 a7 b0 b1 b2 b3 b4 b5 b6 b7"))
+	if goodSize, badSize := good.Size(good.FromAST(node)), bad.Size(bad.FromAST(node)); goodSize != 18 || badSize <= 500 {
+		t.Errorf("Size failed! Expected 18 nodes with a good order and more than 500 with a bad one but got %v and %v !",
+			goodSize, badSize)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var m = NewManager(nil)
+	var builder strings.Builder
+	if err := m.WriteDOT(&builder, m.FromAST(boolparser.MustParse("a & !b"))); err != nil {
+		t.Fatalf("WriteDOT failed with error %v !", err)
+	}
+	var expected = "digraph bdd {\n" +
+		"  node [shape=circle];\n" +
+		"  n5 [label=\"a\"];\n" +
+		"  n5 -> n0 [style=dashed];\n" +
+		"  n5 -> n4;\n" +
+		"  n0 [shape=box, label=\"0\"];\n" +
+		"  n4 [label=\"b\"];\n" +
+		"  n4 -> n1 [style=dashed];\n" +
+		"  n4 -> n0;\n" +
+		"  n1 [shape=box, label=\"1\"];\n" +
+		"}\n"
+	if builder.String() != expected {
+		t.Errorf("WriteDOT on input \"a & !b\" failed! Expected\n%v\nbut got\n%v", expected, builder.String())
+	}
+}
@@ -0,0 +1,44 @@
+package bdd
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteDOT writes the BDD in the DOT language of Graphviz, e.g. for
+// "dot -Tsvg". Dashed edges lead to the low child, i. e. they are taken if the
+// variable is false. Solid edges lead to the high child.
+func (m *Manager) WriteDOT(writer io.Writer, b BDD) error {
+	var lines = []string{"digraph bdd {", `  node [shape=circle];`}
+	var visited = make(map[BDD]bool)
+	var visit func(BDD)
+	visit = func(b BDD) {
+		if visited[b] {
+			return
+		}
+		visited[b] = true
+		if b <= True {
+			var label = "0"
+			if b == True {
+				label = "1"
+			}
+			lines = append(lines, fmt.Sprintf("  n%d [shape=box, label=%q];", b, label))
+			return
+		}
+		var n = m.nodes[b]
+		lines = append(lines,
+			fmt.Sprintf("  n%d [label=%q];", b, m.vars[n.level]),
+			fmt.Sprintf("  n%d -> n%d [style=dashed];", b, n.low),
+			fmt.Sprintf("  n%d -> n%d;", b, n.high))
+		visit(n.low)
+		visit(n.high)
+	}
+	visit(b)
+	lines = append(lines, "}")
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
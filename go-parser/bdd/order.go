@@ -0,0 +1,47 @@
+package bdd
+
+import (
+	"sort"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+)
+
+// OrderByAppearance returns the variables of the expressions in the order of
+// their first appearance from left to right. Variables that appear close to
+// each other in an expression tend to depend on each other, so testing them
+// one after the other keeps the BDD small. This is a good default order.
+func OrderByAppearance(expressions ...ast.Node) []string {
+	var order []string
+	var found = make(map[string]bool)
+	for _, expression := range expressions {
+		ast.Inspect(expression, func(node ast.Node) bool {
+			if val, isVal := node.(ast.Val); isVal && !found[val.Name] {
+				found[val.Name] = true
+				order = append(order, val.Name)
+			}
+			return true
+		})
+	}
+	return order
+}
+
+// OrderByFrequency returns the variables of the expressions ordered by the
+// number of their appearances, the most frequent variable first. Variables
+// that appear equally often keep the order of their first appearance. Testing
+// the variables that matter most first often decides the value early.
+func OrderByFrequency(expressions ...ast.Node) []string {
+	var order = OrderByAppearance(expressions...)
+	var counts = make(map[string]int)
+	for _, expression := range expressions {
+		ast.Inspect(expression, func(node ast.Node) bool {
+			if val, isVal := node.(ast.Val); isVal {
+				counts[val.Name]++
+			}
+			return true
+		})
+	}
+	sort.SliceStable(order, func(i int, j int) bool {
+		return counts[order[i]] > counts[order[j]]
+	})
+	return order
+}
@@ -1,81 +1,157 @@
 package boolparser
 
 import (
-	"container/list"
-
 	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
 	"github.com/m-voit/concepts-of-programming-languages/go-parser/parser"
 )
 
+// grammar holds the parsers for Boolean expressions. They're methods so that
+// the grammar can be configured: if spans is true then every node of the tree
+// is wrapped in an ast.Spanned that tells where it comes from in the text.
+// The keywords may be used instead of the operator symbols.
+type grammar struct {
+	spans bool
+
+	keywords Keywords
+
+	// The combinators of the rules are built once by newGrammar, so that the
+	// parse methods don't build them again on every call.
+	all              parser.Parser[ast.Node]
+	expression       parser.Parser[ast.Node]
+	operators        parser.Parser[ast.Node]
+	not              parser.Parser[ast.Node]
+	exclamationMarks parser.Parser[[]parser.Located[string]]
+	atom             parser.Parser[ast.Node]
+	constant         parser.Parser[ast.Node]
+	variable         parser.Parser[ast.Node]
+}
+
+// newGrammar creates a grammar with the keywords that records spans if spans
+// is true. The rules refer to each other through the parse methods, so the
+// order in which they are built doesn't matter.
+func newGrammar(spans bool, keywords Keywords) *grammar {
+	var g = &grammar{spans: spans, keywords: keywords}
+	g.all = parser.First(parser.AndThen(parser.Parser[ast.Node](g.parseExpression), parser.ExpectEnd))
+	g.expression = parser.First(parser.AndThen(parser.Parser[ast.Node](g.parseOperators), parser.ExpectSpaces))
+	g.operators = parser.OperatorPrecedence(parser.Parser[ast.Node](g.parseNot),
+		parser.InfixLeft(parser.MaybeSpacesBefore(g.expectOperator("&", g.keywords.And)), 5, g.infix(makeAnd)),
+		parser.InfixLeft(expect("^"), 4, g.infix(makeXor)),
+		parser.InfixLeft(parser.MaybeSpacesBefore(g.expectOperator("|", g.keywords.Or)), 3, g.infix(makeOr)),
+		parser.InfixRight(expect("->"), 2, g.infix(makeImplies)),
+		parser.InfixLeft(expect("<->"), 1, g.infix(makeEquiv)))
+	g.not = parser.Map(parser.AndThen(parser.Parser[[]parser.Located[string]](g.parseExclamationMarks), parser.Parser[ast.Node](g.parseAtom)), func(pair parser.Pair[[]parser.Located[string], ast.Node]) ast.Node {
+		return g.makeNots(pair.First, pair.Second)
+	})
+	g.exclamationMarks = parser.Many(parser.MaybeSpacesBefore(parser.Locate(g.expectOperator("!", g.keywords.Not))))
+	g.atom = parser.Longest(parser.Parser[ast.Node](g.parseConstant), parser.Parser[ast.Node](g.parseVariable)).OrElse(parser.Map(parser.MaybeSpacesBefore(parser.Locate(parser.Second(parser.First(parser.AndThen(parser.AndThen(parser.ExpectString("("), parser.Parser[ast.Node](g.parseExpression)), expect(")")))))), g.respan))
+	g.constant = parser.Map(parser.MaybeSpacesBefore(parser.Locate(parser.ExpectString("true").OrElse(parser.ExpectString("false")))), func(constant parser.Located[string]) ast.Node {
+		return g.node(ast.Const{Value: constant.Result == "true"}, constant.Start, constant.End)
+	})
+	g.variable = parser.Map(parser.MaybeSpacesBefore(parser.Locate(parser.ExpectIdentifierExcept(g.keywords.isReserved))), func(name parser.Located[string]) ast.Node {
+		return g.node(ast.Val{Name: name.Result}, name.Start, name.End)
+	})
+	return g
+}
+
+// plainGrammar creates trees without spans, see Parse.
+var plainGrammar = newGrammar(false, DefaultKeywords)
+
+// spanGrammar creates trees with spans, see ParseWithSpans.
+var spanGrammar = newGrammar(true, DefaultKeywords)
+
+// parseAll parses the following grammar: All := Expression ^ End
+//
+// It makes sure that parseExpression consumes the whole Input. If it doesn't,
+// the failure expects the end of the input in addition to everything that
+// parseExpression would have accepted.
+func (g *grammar) parseAll(Input parser.Input) parser.Result[ast.Node] {
+	return g.all(Input)
+}
+
 // parseExpression parses the following grammar: Expression := Or Spaces*
 //
 // The syntax tree is exactly the one returned by Or.
-func parseExpression(Input parser.Input) parser.Result {
-	return parser.Parser(parseOr).AndThen(parser.ExpectSpaces.Optional()).First()(Input)
+func (g *grammar) parseExpression(Input parser.Input) parser.Result[ast.Node] {
+	return g.expression(Input)
 }
 
-// parseOr parses the following grammar: Or := And ^ ("|" ^ Or)?
+// parseOperators parses the following grammar:
+//
+//	Equiv   := Implies ^ ("<->" ^ Implies)*
+//	Implies := Or ^ ("->" ^ Implies)?
+//	Or      := Xor ^ (("|" | "or") ^ Xor)*
+//	Xor     := And ^ ("^" ^ And)*
+//	And     := Not ^ (("&" | "and") ^ Not)*
 //
-// If the parser for ("|" ^ Or)? produces nothing then parseOr will return the
-// tree returned by And. Otherwise parseOr will return a new Or Node containing
-// the sub-trees returned by the recursive calls. parseOr uses expect to parse
-// the symbol "|", i. e. it actually allows for Space* ^ "|".
-func parseOr(Input parser.Input) parser.Result {
-	return parser.Parser(parseAnd).AndThen(expect("|").AndThen(parseOr).Second().Optional()).Convert(makeOr)(Input)
+// Instead of one function per rule the grammar is declared as a table of
+// operators for parser.OperatorPrecedence: "&" binds tightest, followed by
+// "^", "|", "->" and "<->". "->" is right-associative, i. e. "a -> b -> c"
+// results in the tree Implies{a, Implies{b, c}}. The other operators are
+// left-associative, i. e. "a & b & c" results in the tree And{And{a, b}, c}.
+// makeAnd, makeXor, makeOr, makeImplies and makeEquiv create the nodes for the
+// operators. parseOperators uses expect and expectOperator to parse the
+// symbols, i. e. it actually allows for Space* ^ "|", Space* ^ "&" and so on.
+// The keywords "and" and "or" are the ones from the Keywords of the grammar.
+func (g *grammar) parseOperators(Input parser.Input) parser.Result[ast.Node] {
+	return g.operators(Input)
 }
 
-// parseAnd parses the following grammar: And := Not ^ ("&" ^ And)?
+// parseNot parses the following grammar: Not := ("!" | "not")* ^ Atom
 //
-// If the parser for ("&" ^ And)? produces nothing then parseAnd will return the
-// tree returned by Not. Otherwise parseAnd will return a new And Node containing
-// the sub-trees returned by the recursive calls. parseAnd uses expect to parse
-// the symbol "&", i. e. it actually allows for Space* ^ "&".
-func parseAnd(Input parser.Input) parser.Result {
-	return parser.Parser(parseNot).AndThen(expect("&").AndThen(parseAnd).Second().Optional()).Convert(makeAnd)(Input)
+// It delegates parsing ("!" | "not")* to parseExclamationMarks and the
+// construction of Not nodes to makeNots. If there's no exclamation mark then
+// parseNot will return the tree parsed by parseAtom. Otherwise parseNot will
+// wrap the atom in as many Not nodes as there are exclamation marks.
+func (g *grammar) parseNot(Input parser.Input) parser.Result[ast.Node] {
+	return g.not(Input)
 }
 
-// parseNot parses the following grammar: Not := "!"* ^ Atom
-//
-// It delegates parsing "!"* to parseExclamationMarks and the construction of Not
-// nodes to makeNots. If there's no exclamation mark then parseNot will return
-// the tree parsed by parseAtom. Otherwise parseNot will wrap the atom in as many
-// Not nodes as there are exclamation marks.
-func parseNot(Input parser.Input) parser.Result {
-	return parseExclamationMarks.AndThen(parseAtom).Convert(func(arg interface{}) interface{} {
-		var pair = arg.(parser.Pair)
-		return makeNot(pair.First.(int), pair.Second.(ast.Node))
-	})(Input)
-}
-
-// parseExclamationMarks parses the following grammar: "!"*
+// parseExclamationMarks parses the following grammar: ("!" | "not")*
 //
-// It returns the number of exclamation marks in Result.Result as an int.
-// parseExclamationMarks uses expect to parse the symbol "!", i. e. it actually
-// allows for Space* ^ "!".
-var parseExclamationMarks parser.Parser = func(Input parser.Input) parser.Result {
-	return expect("!").Repeated().Convert(func(arg interface{}) interface{} {
-		var list = arg.(*list.List)
-		return list.Len()
-	})(Input)
+// It returns the exclamation marks along with their locations in the Input.
+// The keyword "not" is the one from the Keywords of the grammar. Like expect,
+// parseExclamationMarks actually allows for Space* ^ "!".
+func (g *grammar) parseExclamationMarks(Input parser.Input) parser.Result[[]parser.Located[string]] {
+	return g.exclamationMarks(Input)
 }
 
-// parseAtom parses the followiong grammar: Atom := Variable | "(" ^ Expression ^ ")"
+// parseAtom parses the followiong grammar:
 //
+//	Atom := Constant | Variable | "(" ^ Expression ^ ")"
+//
+// Constants and variables look alike, so parseAtom uses parser.Longest to
+// choose between them: "true" is a Constant, but "trueish" is a Variable.
 // The parenthesis won't appear in the abstract syntax tree. parseAtom uses
-// Parser.First() and Parser.Second() to extract the tree returned by parseExpression.
-func parseAtom(Input parser.Input) parser.Result {
-	return parseVariable.OrElse(expect("(").AndThen(parseExpression).AndThen(expect(")")).First().Second())(Input)
+// parser.First and parser.Second to extract the tree returned by parseExpression.
+// If the grammar records spans then the span of the tree includes the parenthesis.
+func (g *grammar) parseAtom(Input parser.Input) parser.Result[ast.Node] {
+	return g.atom(Input)
+}
+
+// parseConstant parses the following grammar: Constant := "true" | "false"
+//
+// It creates an ast.Const node with the value of the constant.
+func (g *grammar) parseConstant(Input parser.Input) parser.Result[ast.Node] {
+	return g.constant(Input)
 }
 
 // parseVariable parses the following grammar: Variable := [a-zA-Z_][a-zA-Z_0-9]*
 //
-// It delegates parsing the variable name to ExpectIdentifier from the parser
-// combinators package and uses the Convert method on parsers to create the ast.Val node.
-var parseVariable parser.Parser = func(Input parser.Input) parser.Result {
-	return parser.MaybeSpacesBefore(parser.ExpectIdentifier).Convert(func(arg interface{}) interface{} {
-		var name = arg.(string)
-		return ast.Val{Name: name}
-	})(Input)
+// It delegates parsing the variable name to ExpectIdentifierExcept from the
+// parser combinators package, which rejects the keywords of the grammar, and
+// uses parser.Map to create the ast.Val node.
+func (g *grammar) parseVariable(Input parser.Input) parser.Result[ast.Node] {
+	return g.variable(Input)
+}
+
+// expectOperator expects the symbol of an operator or its keyword at the
+// beginning of the Input. If the keyword is empty then only the symbol is
+// allowed.
+func (g *grammar) expectOperator(symbol string, keyword string) parser.Parser[string] {
+	if keyword == "" {
+		return parser.ExpectString(symbol)
+	}
+	return parser.ExpectString(symbol).OrElse(parser.ExpectKeyword(keyword, !g.keywords.CaseSensitive))
 }
 
 // makeNot wraps the node into num ast.Not nodes.
@@ -86,38 +162,32 @@ func makeNot(num int, node ast.Node) ast.Node {
 	return ast.Not{Ex: makeNot(num-1, node)}
 }
 
-// makeAnd takes a Pair of ast.Node as an argument and returns an
-// ast.Node. If the second component of the pair is equal to Nothing{} then it
-// returns the first component of the Pair. If the second component is a Node
-// then makeAnd will create an ast.And node containing the first and the second
-// component of the Pair as sub-nodes.
-func makeAnd(argument interface{}) interface{} {
-	var pair = argument.(parser.Pair)
-	if pair.Second == (parser.Nothing{}) {
-		return pair.First
-	}
-	var firstNode = pair.First.(ast.Node)
-	var secondNode = pair.Second.(ast.Node)
-	return ast.And{LHS: firstNode, RHS: secondNode}
+// makeAnd creates an ast.And node containing lhs and rhs as sub-nodes.
+func makeAnd(lhs ast.Node, rhs ast.Node) ast.Node {
+	return ast.And{LHS: lhs, RHS: rhs}
+}
 
+// makeOr creates an ast.Or node containing lhs and rhs as sub-nodes.
+func makeOr(lhs ast.Node, rhs ast.Node) ast.Node {
+	return ast.Or{LHS: lhs, RHS: rhs}
 }
 
-// makeOr takes a Pair of ast.Node as an argument and returns an
-// ast.Node. If the second component of the pair is equal to Nothing{} then it
-// returns the first component of the Pair. If the second component is a Node
-// then makeOr will create an ast.Or node containing the first and the second
-// component of the Pair as sub-nodes.
-func makeOr(argument interface{}) interface{} {
-	var pair = argument.(parser.Pair)
-	if pair.Second == (parser.Nothing{}) {
-		return pair.First
-	}
-	var firstNode = pair.First.(ast.Node)
-	var secondNode = pair.Second.(ast.Node)
-	return ast.Or{LHS: firstNode, RHS: secondNode}
+// makeXor creates an ast.Xor node containing lhs and rhs as sub-nodes.
+func makeXor(lhs ast.Node, rhs ast.Node) ast.Node {
+	return ast.Xor{LHS: lhs, RHS: rhs}
+}
+
+// makeImplies creates an ast.Implies node containing lhs and rhs as sub-nodes.
+func makeImplies(lhs ast.Node, rhs ast.Node) ast.Node {
+	return ast.Implies{LHS: lhs, RHS: rhs}
+}
+
+// makeEquiv creates an ast.Equiv node containing lhs and rhs as sub-nodes.
+func makeEquiv(lhs ast.Node, rhs ast.Node) ast.Node {
+	return ast.Equiv{LHS: lhs, RHS: rhs}
 }
 
 // expect expects the string s at the beginning of the Input and ignores leading spaces.
-func expect(s string) parser.Parser {
+func expect(s string) parser.Parser[string] {
 	return parser.MaybeSpacesBefore(parser.ExpectString(s))
 }
@@ -1,6 +1,9 @@
 package boolparser
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
@@ -8,35 +11,21 @@ import (
 )
 
 func TestMakeOr(t *testing.T) {
-	var result = makeOr(parser.Pair{ast.Val{"a"}, ast.Val{"b"}})
+	var result = makeOr(ast.Val{"a"}, ast.Val{"b"})
 	var expected ast.Node = ast.Or{ast.Val{"a"}, ast.Val{"b"}}
 	if result != expected {
 		t.Errorf(
-			"makeOr (Pair { Val { \"a\" }, Val { \"b\" }}) failed! Expected %v"+
-				" but got wrong result %v !", expected, result)
-	}
-	result = makeOr(parser.Pair{ast.Val{"a"}, parser.Nothing{}})
-	expected = ast.Val{"a"}
-	if result != expected {
-		t.Errorf(
-			"makeOr (Pair { Val { \"a\" }, Nothing{} }) failed! Expected %v "+
+			"makeOr (Val { \"a\" }, Val { \"b\" }) failed! Expected %v"+
 				" but got wrong result %v !", expected, result)
 	}
 }
 
 func TestMakeAnd(t *testing.T) {
-	var result = makeAnd(parser.Pair{ast.Val{"a"}, ast.Val{"b"}})
+	var result = makeAnd(ast.Val{"a"}, ast.Val{"b"})
 	var expected ast.Node = ast.And{ast.Val{"a"}, ast.Val{"b"}}
 	if result != expected {
 		t.Errorf(
-			"makeAnd (Pair { Val { \"a\" }, Val { \"b\" }}) failed! Expected %v"+
-				" but got wrong result %v !", expected, result)
-	}
-	result = makeAnd(parser.Pair{ast.Val{"a"}, parser.Nothing{}})
-	expected = ast.Val{"a"}
-	if result != expected {
-		t.Errorf(
-			"makeAnd (Pair { Val { \"a\" }, Nothing{} }) failed! Expected %v "+
+			"makeAnd (Val { \"a\" }, Val { \"b\" }) failed! Expected %v"+
 				" but got wrong result %v !", expected, result)
 	}
 }
@@ -59,12 +48,12 @@ func TestMakeNot(t *testing.T) {
 func TestParseVariable(t *testing.T) {
 	var text = "xyz"
 	var expected ast.Node = ast.Val{"xyz"}
-	var result = parseVariable(parser.StringToInput(text))
+	var result = plainGrammar.parseVariable(parser.StringToInput(text))
 	if result.Result != expected {
 		t.Errorf("parseVariable on input \"%v\" failed! Expected %v "+
 			"but got wrong result %v !", text, expected, result.Result)
 	}
-	if result.RemainingInput != nil {
+	if !result.RemainingInput.AtEnd() {
 		var inp = result.RemainingInput.(parser.RuneArrayInput)
 		var rest = inp.Text[inp.CurrentPosition:]
 		t.Errorf("parseVariable didn't eat all the input. Leftover: \"%v\"",
@@ -75,12 +64,12 @@ func TestParseVariable(t *testing.T) {
 func TestParseExclamationMarks(t *testing.T) {
 	var text = "!!!x"
 	var expected int = 3
-	var result = parseExclamationMarks(parser.StringToInput(text))
-	if result.Result != expected {
+	var result = plainGrammar.parseExclamationMarks(parser.StringToInput(text))
+	if len(result.Result) != expected {
 		t.Errorf("parseExclamationMarks on input \"%v\" failed! Expected %d "+
-			"but got wrong result %d !", text, expected, result.Result)
+			"but got wrong result %d !", text, expected, len(result.Result))
 	}
-	if result.RemainingInput != nil {
+	if !result.RemainingInput.AtEnd() {
 		var inp = result.RemainingInput.(parser.RuneArrayInput)
 		var rest = inp.Text[inp.CurrentPosition:]
 		if "x" != string(rest) {
@@ -95,12 +84,12 @@ func TestParseExclamationMarks(t *testing.T) {
 }
 
 func testExp(t *testing.T, text string, expected ast.Node) {
-	var result = parseExpression(parser.StringToInput(text))
+	var result = plainGrammar.parseExpression(parser.StringToInput(text))
 	if result.Result != expected {
 		t.Errorf("parseExpression on input \"%v\" failed! Expected %v "+
 			"but got wrong result %v !", text, expected, result.Result)
 	}
-	if result.RemainingInput != nil {
+	if !result.RemainingInput.AtEnd() {
 		var inp = result.RemainingInput.(parser.RuneArrayInput)
 		var rest = inp.Text[inp.CurrentPosition:]
 		t.Errorf("parseExpression didn't eat all the input. "+
@@ -114,14 +103,267 @@ func TestParseExpression(t *testing.T) {
 	testExp(t, "a|b", ast.Or{ast.Val{"a"}, ast.Val{"b"}})
 	testExp(t, " a &  b", ast.And{ast.Val{"a"}, ast.Val{"b"}})
 	testExp(t, "a   ", ast.Val{"a"})
-	testExp(t, "a&b&c", ast.And{ast.Val{"a"}, ast.And{ast.Val{"b"}, ast.Val{"c"}}})
+	testExp(t, "a&b&c", ast.And{ast.And{ast.Val{"a"}, ast.Val{"b"}}, ast.Val{"c"}})
 	testExp(t, "a&(b&c)", ast.And{ast.Val{"a"}, ast.And{ast.Val{"b"}, ast.Val{"c"}}})
 	testExp(t, "(a&b)&c", ast.And{ast.And{ast.Val{"a"}, ast.Val{"b"}}, ast.Val{"c"}})
-	testExp(t, "a|b|c", ast.Or{ast.Val{"a"}, ast.Or{ast.Val{"b"}, ast.Val{"c"}}})
+	testExp(t, "a|b|c", ast.Or{ast.Or{ast.Val{"a"}, ast.Val{"b"}}, ast.Val{"c"}})
 	testExp(t, "a|(b|c)", ast.Or{ast.Val{"a"}, ast.Or{ast.Val{"b"}, ast.Val{"c"}}})
 	testExp(t, "(a|b)|c", ast.Or{ast.Or{ast.Val{"a"}, ast.Val{"b"}}, ast.Val{"c"}})
 	testExp(t, "!a & b|c&!(d|e)",
 		ast.Or{ast.And{ast.Not{ast.Val{"a"}}, ast.Val{"b"}},
 			ast.And{ast.Val{"c"}, ast.Not{ast.Or{ast.Val{"d"}, ast.Val{"e"}}}}})
+	testExp(t, "a|b&c|d",
+		ast.Or{ast.Or{ast.Val{"a"}, ast.And{ast.Val{"b"}, ast.Val{"c"}}}, ast.Val{"d"}})
+	testExp(t, "true", ast.Const{true})
+	testExp(t, "!false & trueish", ast.And{ast.Not{ast.Const{false}}, ast.Val{"trueish"}})
+	testExp(t, "a^b^c", ast.Xor{ast.Xor{ast.Val{"a"}, ast.Val{"b"}}, ast.Val{"c"}})
+	testExp(t, "a -> b -> c", ast.Implies{ast.Val{"a"}, ast.Implies{ast.Val{"b"}, ast.Val{"c"}}})
+	testExp(t, "a <-> b <-> c", ast.Equiv{ast.Equiv{ast.Val{"a"}, ast.Val{"b"}}, ast.Val{"c"}})
+	testExp(t, "a | b ^ c & d -> e <-> f",
+		ast.Equiv{ast.Implies{ast.Or{ast.Val{"a"}, ast.Xor{ast.Val{"b"}, ast.And{ast.Val{"c"}, ast.Val{"d"}}}},
+			ast.Val{"e"}}, ast.Val{"f"}})
+	testExp(t, "a <-> b -> (c | true)",
+		ast.Equiv{ast.Val{"a"}, ast.Implies{ast.Val{"b"}, ast.Or{ast.Val{"c"}, ast.Const{true}}}})
+
+}
+
+func testSyntaxError(t *testing.T, text string, expected string) {
+	var _, err = Parse(text)
+	if err == nil {
+		t.Errorf("Parse on input \"%v\" must fail but it didn't!", text)
+		return
+	}
+	if err.Error() != expected {
+		t.Errorf("Parse on input \"%v\" failed with the wrong error! "+
+			"Expected\n%v\nbut got\n%v", text, expected, err)
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	testSyntaxError(t, "a & (b | ",
+		"1:10: unexpected end of input, expected \"!\", \"not\", \"true\", \"false\", identifier or \"(\"\n"+
+			"a & (b | \n"+
+			"         ^")
+	testSyntaxError(t, "a & (b",
+		"1:7: unexpected end of input, expected \"&\", \"and\", \"^\", \"|\", \"or\", \"->\", \"<->\" or \")\"\n"+
+			"a & (b\n"+
+			"      ^")
+	testSyntaxError(t, "a &\n  (b | )",
+		"2:8: unexpected ')', expected \"!\", \"not\", \"true\", \"false\", identifier or \"(\"\n"+
+			"  (b | )\n"+
+			"       ^")
+	testSyntaxError(t, "(a\t| !)",
+		"1:7: unexpected ')', expected \"!\", \"not\", \"true\", \"false\", identifier or \"(\"\n"+
+			"(a\t| !)\n"+
+			"  \t   ^")
+
+	var _, err = Parse("a & !")
+	var syntaxError, isSyntaxError = err.(*SyntaxError)
+	if !isSyntaxError {
+		t.Fatalf("Parse must fail with a *SyntaxError but got %v !", err)
+	}
+	var expected = parser.Position{Offset: 5, Line: 1, Column: 6}
+	if syntaxError.Position != expected {
+		t.Errorf("Parse on input \"a & !\" failed at the wrong position! "+
+			"Expected %v but got %v !", expected, syntaxError.Position)
+	}
+}
+
+func TestParse(t *testing.T) {
+	var node, err = Parse(" a & !b ")
+	var expected ast.Node = ast.And{ast.Val{"a"}, ast.Not{ast.Val{"b"}}}
+	if err != nil || node != expected {
+		t.Errorf("Parse on input \" a & !b \" failed! Expected %v "+
+			"but got wrong result %v with error %v !", expected, node, err)
+	}
+
+	testSyntaxError(t, "a & b )garbage",
+		"1:7: unexpected ')', expected \"&\", \"and\", \"^\", \"|\", \"or\", \"->\", \"<->\" or end of input\n"+
+			"a & b )garbage\n"+
+			"      ^")
+	testSyntaxError(t, "a b",
+		"1:3: unexpected 'b', expected \"&\", \"and\", \"^\", \"|\", \"or\", \"->\", \"<->\" or end of input\n"+
+			"a b\n"+
+			"  ^")
+
+	for _, text := range []string{"", "  \n\t"} {
+		if _, err = Parse(text); err != ErrEmptyExpression {
+			t.Errorf("Parse on input %q must fail with ErrEmptyExpression "+
+				"but got %v !", text, err)
+		}
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	var node, err = ParseBytes([]byte("a | !(b & c)"))
+	var expected ast.Node = ast.Or{ast.Val{"a"}, ast.Not{ast.And{ast.Val{"b"}, ast.Val{"c"}}}}
+	if err != nil || node != expected {
+		t.Errorf("ParseBytes on input \"a | !(b & c)\" failed! Expected %v "+
+			"but got wrong result %v with error %v !", expected, node, err)
+	}
+	_, err = ParseBytes([]byte("€ | x"))
+	if err == nil || !strings.HasPrefix(err.Error(), "1:1: unexpected '€'") {
+		t.Errorf("ParseBytes must point at the first code point but got %v !", err)
+	}
+	if _, err = ParseBytes([]byte(" ")); err != ErrEmptyExpression {
+		t.Errorf("ParseBytes on input \" \" must fail with ErrEmptyExpression "+
+			"but got %v !", err)
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	testExp(t, "beta and not legacy", ast.And{ast.Val{"beta"}, ast.Not{ast.Val{"legacy"}}})
+	testExp(t, "android OR Not(organic and nothing)",
+		ast.Or{ast.Val{"android"}, ast.Not{ast.And{ast.Val{"organic"}, ast.Val{"nothing"}}}})
+	testExp(t, "a & b and c | d", ast.Or{ast.And{ast.And{ast.Val{"a"}, ast.Val{"b"}}, ast.Val{"c"}}, ast.Val{"d"}})
+	testSyntaxError(t, "a and or",
+		"1:7: unexpected 'o', expected \"!\", \"not\", \"true\", \"false\", identifier or \"(\"\n"+
+			"a and or\n"+
+			"      ^")
+
+	var german = NewParser(Keywords{Not: "nicht", And: "und", Or: "oder", CaseSensitive: true})
+	var node, err = german.Parse("nicht a und b oder and")
+	var expected ast.Node = ast.Or{ast.And{ast.Not{ast.Val{"a"}}, ast.Val{"b"}}, ast.Val{"and"}}
+	if err != nil || node != expected {
+		t.Errorf("Parse with german keywords failed! Expected %v "+
+			"but got wrong result %v with error %v !", expected, node, err)
+	}
+	if _, err = german.Parse("a UND b"); err == nil {
+		t.Errorf("Parse with case-sensitive keywords must fail on input \"a UND b\"!")
+	}
 
+	node, err = NewParser(Keywords{}).Parse("not | and")
+	expected = ast.Or{ast.Val{"not"}, ast.Val{"and"}}
+	if err != nil || node != expected {
+		t.Errorf("Parse without keywords failed! Expected %v "+
+			"but got wrong result %v with error %v !", expected, node, err)
+	}
+}
+
+// spanned wraps the node in an ast.Spanned from the offset, line and column
+// in start to the ones in end.
+func spanned(node ast.Node, start [3]int, end [3]int) ast.Node {
+	return ast.Spanned{Ex: node, Location: ast.Span{
+		Start: ast.Position{Offset: start[0], Line: start[1], Column: start[2]},
+		End:   ast.Position{Offset: end[0], Line: end[1], Column: end[2]},
+	}}
+}
+
+func TestParseWithSpans(t *testing.T) {
+	var node, err = ParseWithSpans("!(a &\n b) | c")
+	var a = spanned(ast.Val{"a"}, [3]int{2, 1, 3}, [3]int{3, 1, 4})
+	var b = spanned(ast.Val{"b"}, [3]int{7, 2, 2}, [3]int{8, 2, 3})
+	var c = spanned(ast.Val{"c"}, [3]int{12, 2, 7}, [3]int{13, 2, 8})
+	var and = spanned(ast.And{a, b}, [3]int{1, 1, 2}, [3]int{9, 2, 4})
+	var not = spanned(ast.Not{and}, [3]int{0, 1, 1}, [3]int{9, 2, 4})
+	var expected = spanned(ast.Or{not, c}, [3]int{0, 1, 1}, [3]int{13, 2, 8})
+	if err != nil || node != expected {
+		t.Errorf("ParseWithSpans on input \"!(a &\\n b) | c\" failed! "+
+			"Expected %#v but got wrong result %#v with error %v !", expected, node, err)
+	}
+	if span := node.(ast.Spanner).Span(); span.End.Line != 2 || span.End.Column != 8 {
+		t.Errorf("Span of %v ends at wrong position %v !", node, span.End)
+	}
+
+	for _, text := range []string{"a", " ! ! a ", "a & b | !(c | d) & e", "((a))\n&\tb",
+		"a ^ true -> b <-> !false", "not  not a or b"} {
+		var plain, _ = Parse(text)
+		var withSpans, err = ParseWithSpans(text)
+		if err != nil || ast.StripSpans(withSpans) != plain {
+			t.Errorf("ParseWithSpans on input %q failed! Expected %v "+
+				"without spans but got wrong result %v with error %v !", text, plain, withSpans, err)
+		}
+	}
+
+	testSyntaxError(t, "a & (b", "1:7: unexpected end of input, expected \"&\", \"and\", \"^\", \"|\", \"or\", \"->\", \"<->\" or \")\"\n"+
+		"a & (b\n"+
+		"      ^")
+	if _, err = ParseWithSpans(" "); err != ErrEmptyExpression {
+		t.Errorf("ParseWithSpans on input \" \" must fail with ErrEmptyExpression "+
+			"but got %v !", err)
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	var expected ast.Node = ast.Or{ast.Val{"a"}, ast.Val{"b"}}
+	if node := MustParse("a | b"); node != expected {
+		t.Errorf("MustParse on input \"a | b\" failed! Expected %v "+
+			"but got wrong result %v !", expected, node)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustParse on input \"a |\" must panic but it didn't!")
+		}
+	}()
+	MustParse("a |")
+}
+
+// nestedExpression returns an expression with depth nested parentheses like
+// "!(a0 & (a1 | (a2 & a3)))".
+func nestedExpression(depth int) string {
+	var builder strings.Builder
+	for level := 0; level < depth; level++ {
+		var operator = [2]string{"&", "|"}[level%2]
+		fmt.Fprintf(&builder, "!(a%d %v ", level, operator)
+	}
+	builder.WriteString("z")
+	builder.WriteString(strings.Repeat(")", depth))
+	return builder.String()
+}
+
+// BenchmarkParseNested parses nested parentheses the way Parse does. The time
+// per level stays the same since the grammar parses every atom once.
+func BenchmarkParseNested(b *testing.B) {
+	for _, depth := range []int{10, 100, 1000} {
+		var text = nestedExpression(depth)
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				if !plainGrammar.parseAll(parser.StringToInput(text)).Success {
+					b.Fatal("parseAll failed")
+				}
+			}
+		})
+	}
+}
+
+// failingReader returns its text and then an error.
+type failingReader struct {
+	text string
+}
+
+func (reader *failingReader) Read(buffer []byte) (int, error) {
+	if reader.text == "" {
+		return 0, errors.New("disk on fire")
+	}
+	var count = copy(buffer, reader.text)
+	reader.text = reader.text[count:]
+	return count, nil
+}
+
+func TestParseReader(t *testing.T) {
+	// The text is much longer than a chunk of the parser.ReaderInput.
+	var text = nestedExpression(100) + strings.Repeat("\n| b", 3000)
+	var expected, _ = Parse(text)
+	var node, err = ParseReader(strings.NewReader(text))
+	if err != nil || node != expected {
+		t.Errorf("ParseReader on a text of %d bytes failed! Expected %v but got wrong result %v with error %v !",
+			len(text), expected, node, err)
+	}
+
+	node, err = ParseReader(strings.NewReader(text + "\n&"))
+	if err == nil || err.Error() != "3002:2: unexpected end of input, expected \"!\", \"not\", \"true\", \"false\", identifier or \"(\"" {
+		t.Errorf("ParseReader on input ending in \"&\" failed! Expected a syntax error "+
+			"in line 3002 but got wrong result %v with error %v !", node, err)
+	}
+	node, err = ParseReader(strings.NewReader("a &\n  b )"))
+	if err == nil || err.Error() != "2:5: unexpected input, expected \"&\", \"and\", \"^\", \"|\", \"or\", \"->\", \"<->\" or end of input" {
+		t.Errorf("ParseReader on input \"a &\\n  b )\" failed! Expected a syntax error "+
+			"at 2:5 but got wrong result %v with error %v !", node, err)
+	}
+	if _, err = ParseReader(strings.NewReader(" \n\t")); err != ErrEmptyExpression {
+		t.Errorf("ParseReader on input \" \\n\\t\" must fail with ErrEmptyExpression but got %v !", err)
+	}
+	if _, err = ParseReader(&failingReader{"a & b"}); err == nil || err.Error() != "disk on fire" {
+		t.Errorf("ParseReader on a failing reader must return the read error but got %v !", err)
+	}
 }
@@ -0,0 +1,84 @@
+package boolparser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/parser"
+)
+
+// SyntaxError is the error for text that isn't a valid Boolean expression.
+// Its message points at the location where parsing got stuck, e.g.
+//
+//	1:10: unexpected end of input, expected "!", "not", "true", "false", identifier or "("
+//	a & (b |
+//	         ^
+type SyntaxError struct {
+
+	// Text is the whole text that was parsed. It's empty if the text was read
+	// by ParseReader, which doesn't keep it. The message then lacks the line
+	// with the caret.
+	Text string
+
+	// Position is the location of the furthest failure in Text.
+	Position parser.Position
+
+	// Failure is the furthest failure reported by the parser.
+	Failure *parser.Failure
+}
+
+// newSyntaxError converts the failure of a parse of text into a SyntaxError.
+func newSyntaxError(text string, failure *parser.Failure) *SyntaxError {
+	var runes = []rune(text)
+	var offset = failure.Offset
+	if offset > len(runes) {
+		offset = len(runes)
+	}
+	var position = parser.RuneArrayInput{Text: runes, CurrentPosition: offset}.Position()
+	return &SyntaxError{text, position, failure}
+}
+
+// Error implements the error interface.
+func (err *SyntaxError) Error() string {
+	if err.Text == "" {
+		var unexpected = "input"
+		if err.Failure.EndOfInput {
+			unexpected = "end of input"
+		}
+		var message = fmt.Sprintf("%d:%d: unexpected %s", err.Position.Line, err.Position.Column, unexpected)
+		if expectation := err.Failure.Expectation(); expectation != "" {
+			message += ", " + expectation
+		}
+		return message
+	}
+	var lines = strings.Split(err.Text, "\n")
+	var line = strings.TrimSuffix(lines[err.Position.Line-1], "\r")
+	var lineRunes = []rune(line)
+	var unexpected = "end of input"
+	if err.Position.Column <= len(lineRunes) {
+		unexpected = fmt.Sprintf("%q", lineRunes[err.Position.Column-1])
+	} else if err.Position.Offset < len([]rune(err.Text)) {
+		unexpected = "end of line"
+	}
+	var message = fmt.Sprintf("%d:%d: unexpected %s",
+		err.Position.Line, err.Position.Column, unexpected)
+	if expectation := err.Failure.Expectation(); expectation != "" {
+		message += ", " + expectation
+	}
+	return message + "\n" + line + "\n" + caret(lineRunes, err.Position.Column)
+}
+
+// caret returns a line that puts a ^ below the column of line. It keeps the
+// tabs of line so that the ^ lines up in terminals, too.
+func caret(line []rune, column int) string {
+	var builder strings.Builder
+	for index := 0; index < column-1; index++ {
+		if index < len(line) && line[index] == '\t' {
+			builder.WriteRune('\t')
+		} else {
+			builder.WriteRune(' ')
+		}
+	}
+	builder.WriteRune('^')
+	return builder.String()
+}
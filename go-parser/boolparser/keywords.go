@@ -0,0 +1,37 @@
+package boolparser
+
+import "strings"
+
+// Keywords are the words that may be written instead of the operator symbols,
+// e.g. "beta and not legacy" instead of "beta & !legacy". The keywords are
+// reserved words, i. e. they can't be used as variables. An empty word turns
+// the keyword off.
+type Keywords struct {
+
+	// Not is the word for "!".
+	Not string
+
+	// And is the word for "&".
+	And string
+
+	// Or is the word for "|".
+	Or string
+
+	// CaseSensitive is true if the keywords have to be written exactly as
+	// given. Otherwise "AND" and "And" are the keyword "and", too.
+	CaseSensitive bool
+}
+
+// DefaultKeywords are the keywords used by Parse: "not", "and" and "or" in any
+// case.
+var DefaultKeywords = Keywords{Not: "not", And: "and", Or: "or"}
+
+// isReserved returns true if the name is one of the keywords.
+func (keywords Keywords) isReserved(name string) bool {
+	for _, keyword := range []string{keywords.Not, keywords.And, keywords.Or} {
+		if keyword == name || !keywords.CaseSensitive && keyword != "" && strings.EqualFold(keyword, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package boolparser
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/parser"
+)
+
+// ErrEmptyExpression is returned by Parse if the text contains nothing but
+// spaces.
+var ErrEmptyExpression = errors.New("empty expression")
+
+// Parser parses Boolean expressions that may contain keywords instead of
+// operator symbols. Create a Parser with NewParser if you need keywords other
+// than the DefaultKeywords, otherwise use the functions Parse, ParseBytes,
+// ParseReader and ParseWithSpans. A Parser may be used by several goroutines
+// at once.
+type Parser struct {
+	plain *grammar
+	spans *grammar
+}
+
+// NewParser creates a Parser that accepts the keywords. Use Keywords{} for a
+// Parser that accepts operator symbols only.
+func NewParser(keywords Keywords) *Parser {
+	return &Parser{newGrammar(false, keywords), newGrammar(true, keywords)}
+}
+
+// defaultParser is the Parser with the DefaultKeywords.
+var defaultParser = &Parser{plainGrammar, spanGrammar}
+
+// Parse parses the text as a Boolean expression and returns its abstract
+// syntax tree. The whole text has to be a valid expression, trailing input is
+// an error. If the text isn't valid, the error is a *SyntaxError pointing at
+// the location where parsing got stuck. If the text is empty or contains
+// nothing but spaces, the error is ErrEmptyExpression.
+func (p *Parser) Parse(text string) (ast.Node, error) {
+	if strings.Trim(text, " \t\n\r") == "" {
+		return nil, ErrEmptyExpression
+	}
+	var result = p.plain.parseAll(parser.StringToInput(text))
+	if !result.Success {
+		return nil, newSyntaxError(text, result.Failure)
+	}
+	return result.Result, nil
+}
+
+// ParseBytes is like Parse for UTF-8 text in a byte slice. It parses the text
+// without copying it, which saves memory on large texts.
+func (p *Parser) ParseBytes(text []byte) (ast.Node, error) {
+	if len(bytes.Trim(text, " \t\n\r")) == 0 {
+		return nil, ErrEmptyExpression
+	}
+	var result = p.plain.parseAll(parser.BytesToInput(text))
+	if !result.Success {
+		return nil, newSyntaxError(string(text), result.Failure)
+	}
+	return result.Result, nil
+}
+
+// ParseReader is like Parse for UTF-8 text read from the reader. It reads the
+// text in chunks through a parser.ReaderInput, so it doesn't need the text as
+// a string. The grammar may backtrack to the beginning of the text, so the
+// chunks stay in memory until parsing is done. Since the text isn't kept
+// afterwards, a *SyntaxError has no Text and its message lacks the line with
+// the caret. Read errors are returned as they are.
+func (p *Parser) ParseReader(reader io.Reader) (ast.Node, error) {
+	var counter = &lineCounter{reader: reader, starts: []int{0}}
+	var Input = parser.ReaderToInput(counter)
+	for strings.ContainsRune(" \t\n\r", Input.CurrentCodePoint()) {
+		Input = Input.RemainingInput()
+	}
+	if err := Input.(parser.ReaderInput).Err(); err != nil {
+		return nil, err
+	}
+	if Input.AtEnd() {
+		return nil, ErrEmptyExpression
+	}
+	var result = p.plain.parseAll(Input)
+	if err := Input.(parser.ReaderInput).Err(); err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		var at = position(result.Failure.Offset, counter.starts)
+		return nil, &SyntaxError{"", parser.Position{Offset: at.Offset, Line: at.Line, Column: at.Column}, result.Failure}
+	}
+	return result.Result, nil
+}
+
+// lineCounter passes the text of a reader through and records the offsets of
+// the first code points of the lines like lineStarts, so that ParseReader can
+// locate a failure without keeping the text.
+type lineCounter struct {
+	reader io.Reader
+	offset int
+	starts []int
+}
+
+// Read implements the io.Reader interface.
+func (counter *lineCounter) Read(buffer []byte) (int, error) {
+	var count, err = counter.reader.Read(buffer)
+	for _, b := range buffer[:count] {
+		if !utf8.RuneStart(b) {
+			continue
+		}
+		counter.offset++
+		if b == '\n' {
+			counter.starts = append(counter.starts, counter.offset)
+		}
+	}
+	return count, err
+}
+
+// Parse parses the text with the DefaultKeywords, see Parser.Parse.
+func Parse(text string) (ast.Node, error) {
+	return defaultParser.Parse(text)
+}
+
+// ParseBytes parses the text with the DefaultKeywords, see Parser.ParseBytes.
+func ParseBytes(text []byte) (ast.Node, error) {
+	return defaultParser.ParseBytes(text)
+}
+
+// ParseReader parses the text from the reader with the DefaultKeywords, see
+// Parser.ParseReader.
+func ParseReader(reader io.Reader) (ast.Node, error) {
+	return defaultParser.ParseReader(reader)
+}
+
+// MustParse is like Parse but panics if the text isn't a valid expression.
+// It simplifies the initialization of global variables holding expressions.
+func MustParse(text string) ast.Node {
+	var node, err = Parse(text)
+	if err != nil {
+		panic("boolparser: Parse(" + strconv.Quote(text) + "): " + err.Error())
+	}
+	return node
+}
@@ -0,0 +1,122 @@
+package boolparser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/parser"
+)
+
+// ParseWithSpans parses the text with the DefaultKeywords, see
+// Parser.ParseWithSpans.
+func ParseWithSpans(text string) (ast.Node, error) {
+	return defaultParser.ParseWithSpans(text)
+}
+
+// ParseWithSpans is like Parse but wraps every node of the tree in an
+// ast.Spanned that holds the Span of the node in the text. Use the Span
+// method to find out where a sub-expression comes from, e.g. to highlight it
+// in an editor. ast.StripSpans turns the tree into the one that Parse returns.
+//
+// The Span of a parenthesized expression includes the parenthesis, the Span of
+// a Not node starts at its exclamation mark. Spaces around a node don't belong
+// to its Span.
+func (p *Parser) ParseWithSpans(text string) (ast.Node, error) {
+	if strings.Trim(text, " \t\n\r") == "" {
+		return nil, ErrEmptyExpression
+	}
+	var result = p.spans.parseAll(parser.StringToInput(text))
+	if !result.Success {
+		return nil, newSyntaxError(text, result.Failure)
+	}
+	return fillPositions(result.Result, lineStarts(text)), nil
+}
+
+// node wraps the node in an ast.Spanned from start to end if the grammar
+// records spans. The Spans contain offsets only, fillPositions adds the lines
+// and columns once the whole tree has been parsed.
+func (g *grammar) node(node ast.Node, start parser.Input, end parser.Input) ast.Node {
+	if !g.spans {
+		return node
+	}
+	return ast.Spanned{Ex: node, Location: ast.Span{
+		Start: ast.Position{Offset: start.Offset()},
+		End:   ast.Position{Offset: end.Offset()},
+	}}
+}
+
+// infix wraps the function that creates the node for an infix operator. If
+// the grammar records spans then the node spans from lhs to rhs.
+func (g *grammar) infix(makeNode func(ast.Node, ast.Node) ast.Node) func(ast.Node, ast.Node) ast.Node {
+	if !g.spans {
+		return makeNode
+	}
+	return func(lhs ast.Node, rhs ast.Node) ast.Node {
+		return ast.Spanned{Ex: makeNode(lhs, rhs), Location: ast.Span{
+			Start: lhs.(ast.Spanned).Location.Start,
+			End:   rhs.(ast.Spanned).Location.End,
+		}}
+	}
+}
+
+// respan replaces the Span of a parenthesized expression by the Span that
+// includes the parenthesis.
+func (g *grammar) respan(located parser.Located[ast.Node]) ast.Node {
+	if !g.spans {
+		return located.Result
+	}
+	return g.node(located.Result.(ast.Spanned).Ex, located.Start, located.End)
+}
+
+// makeNots wraps the atom into one ast.Not node per exclamation mark. If the
+// grammar records spans then every Not node spans from its exclamation mark
+// to the end of the atom.
+func (g *grammar) makeNots(marks []parser.Located[string], atom ast.Node) ast.Node {
+	if !g.spans || len(marks) == 0 {
+		return makeNot(len(marks), atom)
+	}
+	var end = atom.(ast.Spanned).Location.End
+	for index := len(marks) - 1; index >= 0; index-- {
+		atom = ast.Spanned{Ex: ast.Not{Ex: atom}, Location: ast.Span{
+			Start: ast.Position{Offset: marks[index].Start.Offset()},
+			End:   end,
+		}}
+	}
+	return atom
+}
+
+// lineStarts returns the offsets of the first code points of the lines in text.
+func lineStarts(text string) []int {
+	var starts = []int{0}
+	var offset = 0
+	for _, codePoint := range text {
+		offset++
+		if codePoint == '\n' {
+			starts = append(starts, offset)
+		}
+	}
+	return starts
+}
+
+// position adds the line and the column to a Position that holds an offset.
+func position(offset int, lineStarts []int) ast.Position {
+	var line = sort.Search(len(lineStarts), func(index int) bool {
+		return lineStarts[index] > offset
+	})
+	return ast.Position{Offset: offset, Line: line, Column: offset - lineStarts[line-1] + 1}
+}
+
+// fillPositions adds the lines and columns to the Spans in the tree.
+func fillPositions(node ast.Node, lineStarts []int) ast.Node {
+	return ast.Rewrite(node, func(node ast.Node) ast.Node {
+		var spanned, isSpanned = node.(ast.Spanned)
+		if !isSpanned {
+			return node
+		}
+		return ast.Spanned{Ex: spanned.Ex, Location: ast.Span{
+			Start: position(spanned.Location.Start.Offset, lineStarts),
+			End:   position(spanned.Location.End.Offset, lineStarts),
+		}}
+	})
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// runCheck checks the syntax of an expression. It prints nothing and exits
+// with 0 if the expression is valid and prints the syntax error and exits with
+// 1 otherwise.
+func runCheck(args []string, env *environment) int {
+	var flags = flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	var verbose = flags.Bool("v", false, "print \"ok\" if the expression is valid")
+	flags.Usage = func() {
+		fmt.Fprintln(env.stderr, "usage: boolexpr check [flags] [expression]")
+		fmt.Fprintln(env.stderr, "\nReads the expression from stdin if there is no argument.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if _, ok := readExpression(flags.Args(), env); !ok {
+		return 1
+	}
+	if *verbose {
+		fmt.Fprintln(env.stdout, "ok")
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+)
+
+// runEval evaluates an expression and prints "true" or "false". The values of
+// the variables are read from a JSON file, from the environment variables and
+// from the -values flags, where the latter ones override the former ones.
+func runEval(args []string, env *environment) int {
+	var flags = flag.NewFlagSet("eval", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	var values assignment
+	flags.Var(&values, "values", "values of the variables, e.g. a=true,b=0")
+	var file = flags.String("file", "", "read the values from a JSON object in the `file`, e.g. {\"a\": true}")
+	var fromEnv = flags.Bool("env", false, "read the values from the environment variables named like the variables")
+	var kleene = flags.Bool("kleene", false, "print \"unknown\" if the value depends on unset variables")
+	flags.Usage = func() {
+		fmt.Fprintln(env.stderr, "usage: boolexpr eval [flags] [expression]")
+		fmt.Fprintln(env.stderr, "\nReads the expression from stdin if there is no argument.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	var node, ok = readExpression(flags.Args(), env)
+	if !ok {
+		return 1
+	}
+	var vars, err = collectValues(node, *file, *fromEnv, values, env)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "boolexpr: %v\n", err)
+		return 1
+	}
+	if *kleene {
+		fmt.Fprintln(env.stdout, ast.EvalKleene(node, vars))
+		return 0
+	}
+	value, err := ast.EvalStrict(node, vars)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "boolexpr: %v\n", err)
+		return 1
+	}
+	fmt.Fprintln(env.stdout, value)
+	return 0
+}
+
+// collectValues merges the values of the variables from the file, the
+// environment and the flags.
+func collectValues(node ast.Node, file string, fromEnv bool, values assignment, env *environment) (map[string]bool, error) {
+	var vars = make(map[string]bool)
+	if file != "" {
+		var data, err = os.ReadFile(file)
+		if err == nil {
+			err = json.Unmarshal(data, &vars)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading values from %s: %w", file, err)
+		}
+	}
+	if fromEnv {
+		for _, name := range ast.Vars(node) {
+			var text, found = env.lookupEnv(name)
+			if !found {
+				continue
+			}
+			var value, err = strconv.ParseBool(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of environment variable %s: %q", name, text)
+			}
+			vars[name] = value
+		}
+	}
+	for name, value := range values {
+		vars[name] = value
+	}
+	return vars, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/format"
+)
+
+// runFmt prints an expression with minimal parentheses.
+func runFmt(args []string, env *environment) int {
+	var flags = flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	var keywords = flags.Bool("keywords", false, "write the keywords not, and and or instead of !, & and |")
+	var compact = flags.Bool("compact", false, "omit the spaces around the operators")
+	flags.Usage = func() {
+		fmt.Fprintln(env.stderr, "usage: boolexpr fmt [flags] [expression]")
+		fmt.Fprintln(env.stderr, "\nReads the expression from stdin if there is no argument.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	var node, ok = readExpression(flags.Args(), env)
+	if !ok {
+		return 1
+	}
+	var style = format.DefaultStyle
+	if *keywords {
+		style = format.KeywordStyle
+	}
+	style.Compact = *compact
+	fmt.Fprintln(env.stdout, format.Format(node, style))
+	return 0
+}
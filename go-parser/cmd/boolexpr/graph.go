@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/graph"
+)
+
+// runGraph prints the parse tree of an expression as a DOT or Mermaid graph.
+func runGraph(args []string, env *environment) int {
+	var flags = flag.NewFlagSet("graph", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	var formatName = flags.String("format", "dot", "output `format`: dot or mermaid")
+	var values assignment
+	flags.Var(&values, "values", "colour the nodes by their values for the `assignment`, e.g. a=true,b=0")
+	flags.Usage = func() {
+		fmt.Fprintln(env.stderr, "usage: boolexpr graph [flags] [expression]")
+		fmt.Fprintln(env.stderr, "\nReads the expression from stdin if there is no argument.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	var format, err = graph.ParseFormat(*formatName)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "boolexpr: %v\n", err)
+		return 2
+	}
+	var node, ok = readExpression(flags.Args(), env)
+	if !ok {
+		return 1
+	}
+	if err := graph.Write(env.stdout, node, format, graph.Options{Values: values}); err != nil {
+		fmt.Fprintf(env.stderr, "boolexpr: %v\n", err)
+		return 1
+	}
+	return 0
+}
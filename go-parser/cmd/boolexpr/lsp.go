@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/lsp"
+)
+
+// runLSP runs a language server on stdin and stdout.
+func runLSP(args []string, env *environment) int {
+	var flags = flag.NewFlagSet("lsp", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	var schemaFile = flags.String("schema", "", "read the known variables and the YAML keys of rules from the JSON `file`, "+
+		"e.g. {\"variables\": [{\"name\": \"a\", \"description\": \"...\"}], \"keys\": [\"when\"]}")
+	flags.Usage = func() {
+		fmt.Fprintln(env.stderr, "usage: boolexpr lsp [flags]")
+		fmt.Fprintln(env.stderr, "\nSpeaks the Language Server Protocol on stdin and stdout.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+	var schema lsp.Schema
+	if *schemaFile != "" {
+		var data, err = os.ReadFile(*schemaFile)
+		if err == nil {
+			err = json.Unmarshal(data, &schema)
+		}
+		if err != nil {
+			fmt.Fprintf(env.stderr, "boolexpr: reading schema from %s: %v\n", *schemaFile, err)
+			return 1
+		}
+	}
+	if err := lsp.NewServer(schema).Serve(env.stdin, env.stdout); err != nil {
+		fmt.Fprintf(env.stderr, "boolexpr: %v\n", err)
+		return 1
+	}
+	return 0
+}
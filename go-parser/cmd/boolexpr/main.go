@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+)
+
+// command is a subcommand of boolexpr. It gets the arguments after the name
+// of the subcommand and returns the exit code.
+type command struct {
+	run   func(args []string, env *environment) int
+	usage string
+}
+
+// commands are the subcommands of boolexpr by name.
+var commands = map[string]command{
+	"check": {runCheck, "check the syntax of an expression"},
+	"eval":  {runEval, "evaluate an expression"},
+	"fmt":   {runFmt, "format an expression with minimal parentheses"},
+	"graph": {runGraph, "print the parse tree of an expression for Graphviz or Mermaid"},
+	"lsp":   {runLSP, "run a language server on stdin and stdout"},
+	"parse": {runParse, "print the parse tree of an expression"},
+	"repl":  {runREPL, "evaluate expressions interactively"},
+	"table": {runTable, "print the truth table of an expression"},
+	"vars":  {runVars, "print the variables of an expression"},
+}
+
+// environment holds the standard streams and the environment variables of
+// boolexpr, so that the tests can replace them.
+type environment struct {
+	stdin     io.Reader
+	stdout    io.Writer
+	stderr    io.Writer
+	lookupEnv func(name string) (string, bool)
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &environment{os.Stdin, os.Stdout, os.Stderr, os.LookupEnv}))
+}
+
+// run runs the subcommand named by the first argument and returns the exit
+// code: 0 on success, 1 if the subcommand failed and 2 for usage errors.
+func run(args []string, env *environment) int {
+	if len(args) == 0 {
+		usage(env.stderr)
+		return 2
+	}
+	var command, found = commands[args[0]]
+	if !found {
+		fmt.Fprintf(env.stderr, "boolexpr: unknown command %q\n", args[0])
+		usage(env.stderr)
+		return 2
+	}
+	return command.run(args[1:], env)
+}
+
+// usage prints the subcommands.
+func usage(writer io.Writer) {
+	var names = make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(writer, "usage: boolexpr <command> [flags] [expression]")
+	fmt.Fprintln(writer, "\ncommands:")
+	for _, name := range names {
+		fmt.Fprintf(writer, "  %-8s %s\n", name, commands[name].usage)
+	}
+}
+
+// readText returns the arguments joined by spaces or stdin if there are no
+// arguments. Errors are printed to stderr.
+func readText(args []string, env *environment) (string, bool) {
+	if len(args) > 0 {
+		return strings.Join(args, " "), true
+	}
+	var input, err = io.ReadAll(env.stdin)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "boolexpr: %v\n", err)
+		return "", false
+	}
+	return string(input), true
+}
+
+// readExpression parses the expression from the arguments or from stdin if
+// there are no arguments. Errors are printed to stderr.
+func readExpression(args []string, env *environment) (ast.Node, bool) {
+	var text, ok = readText(args, env)
+	if !ok {
+		return nil, false
+	}
+	var node, err = boolparser.Parse(text)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "boolexpr: %v\n", err)
+		return nil, false
+	}
+	return node, true
+}
+
+// assignment is a flag with values of variables like "a=true,b=0". The flag
+// may be repeated.
+type assignment map[string]bool
+
+// String implements the flag.Value interface.
+func (values assignment) String() string {
+	var pairs []string
+	for name, value := range values {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", name, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set implements the flag.Value interface. The values are parsed by
+// strconv.ParseBool.
+func (values *assignment) Set(text string) error {
+	if *values == nil {
+		*values = make(assignment)
+	}
+	for _, pair := range strings.Split(text, ",") {
+		var name, valueText, found = strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return fmt.Errorf("expected name=value but got %q", pair)
+		}
+		var value, err = strconv.ParseBool(strings.TrimSpace(valueText))
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %q", name, valueText)
+		}
+		(*values)[name] = value
+	}
+	return nil
+}
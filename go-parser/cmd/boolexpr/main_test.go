@@ -0,0 +1,177 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testEnv are the environment variables of boolexpr in the tests.
+var testEnv = map[string]string{"a": "true", "b": "0", "c": "maybe"}
+
+// testRun runs boolexpr with the arguments and the stdin and checks the exit
+// code and the output to stdout.
+func testRun(t *testing.T, args []string, stdin string, code int, stdout string) {
+	var out, errors strings.Builder
+	var result = run(args, &environment{strings.NewReader(stdin), &out, &errors, func(name string) (string, bool) {
+		var value, found = testEnv[name]
+		return value, found
+	}})
+	if result != code || out.String() != stdout {
+		t.Errorf("boolexpr %v failed! Expected exit code %d and output\n%v\n"+
+			"but got %d and\n%v\nwith errors\n%v", args, code, stdout, result, out.String(), errors.String())
+	}
+}
+
+func TestRun(t *testing.T) {
+	testRun(t, nil, "", 2, "")
+	testRun(t, []string{"unknown"}, "", 2, "")
+}
+
+func TestTable(t *testing.T) {
+	testRun(t, []string{"table", "-format", "csv", "a", "&", "b"}, "", 0,
+		"a,b,a & b\n0,0,0\n0,1,0\n1,0,0\n1,1,1\n")
+	testRun(t, []string{"table", "-sub"}, "!a\n", 0,
+		"a  !a\n"+
+			"-  --\n"+
+			"0  1\n"+
+			"1  0\n")
+	testRun(t, []string{"table", "a &"}, "", 1, "")
+	testRun(t, []string{"table", "-max-vars", "1", "a & b"}, "", 1, "")
+	testRun(t, []string{"table", "-format", "html", "a"}, "", 2, "")
+}
+
+func TestGraph(t *testing.T) {
+	testRun(t, []string{"graph", "-format", "mermaid", "-values", "a=1", "!a"}, "", 0,
+		"flowchart TD\n"+
+			"  n0[\"!<br/>false\"]\n"+
+			"  n0 --> n1\n"+
+			"  n1[\"a<br/>true\"]\n"+
+			"  classDef trueValue fill:#a6e3a1\n"+
+			"  classDef falseValue fill:#f38ba8\n"+
+			"  classDef unknownValue fill:#d9d9d9\n"+
+			"  class n0 falseValue\n"+
+			"  class n1 trueValue\n")
+	testRun(t, []string{"graph", "-values", "a=maybe", "a"}, "", 2, "")
+	testRun(t, []string{"graph", "-format", "svg", "a"}, "", 2, "")
+	testRun(t, []string{"graph", "a |"}, "", 1, "")
+}
+
+func TestParse(t *testing.T) {
+	testRun(t, []string{"parse", "a & !b"}, "", 0, "&\n  'a'\n  !\n    'b'\n")
+	testRun(t, []string{"parse", "-spans"}, "a |\n b", 0, "|  1:1-2:3\n  'a'  1:1-1:2\n  'b'  2:2-2:3\n")
+	testRun(t, []string{"parse", "-format", "ast", "a -> b"}, "", 0, "->('a','b')\n")
+	testRun(t, []string{"parse", "-format", "json", "!true"}, "", 0,
+		`{"version":1,"expression":{"type":"not","operand":{"type":"const","value":true}}}`+"\n")
+	testRun(t, []string{"parse", "-format", "xml", "a"}, "", 2, "")
+	testRun(t, []string{"parse", "(a"}, "", 1, "")
+}
+
+func TestEval(t *testing.T) {
+	var file = filepath.Join(t.TempDir(), "values.json")
+	if err := os.WriteFile(file, []byte(`{"a": false, "d": true}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	testRun(t, []string{"eval", "-values", "a=1,b=false", "a & !b"}, "", 0, "true\n")
+	testRun(t, []string{"eval", "-env"}, "a & !b", 0, "true\n")
+	testRun(t, []string{"eval", "-file", file, "a | d"}, "", 0, "true\n")
+	testRun(t, []string{"eval", "-file", file, "-env", "a"}, "", 0, "true\n")
+	testRun(t, []string{"eval", "-file", file, "-values", "d=0", "d"}, "", 0, "false\n")
+	testRun(t, []string{"eval", "-kleene", "-values", "a=0", "a -> z"}, "", 0, "true\n")
+	testRun(t, []string{"eval", "-kleene", "a | z"}, "", 0, "unknown\n")
+	testRun(t, []string{"eval", "a | z"}, "", 1, "")
+	testRun(t, []string{"eval", "-env", "c"}, "", 1, "")
+	testRun(t, []string{"eval", "-file", file + ".missing", "a"}, "", 1, "")
+	testRun(t, []string{"eval", "-values", "a"}, "", 2, "")
+}
+
+func TestFmt(t *testing.T) {
+	testRun(t, []string{"fmt", "((a & b)) | (c)"}, "", 0, "a & b | c\n")
+	testRun(t, []string{"fmt", "-keywords", "-compact"}, "!a & (b | c)", 0, "not a and (b or c)\n")
+	testRun(t, []string{"fmt", "-compact", "a ^ (b -> c)"}, "", 0, "a^(b->c)\n")
+}
+
+func TestVars(t *testing.T) {
+	testRun(t, []string{"vars", "z & a | z"}, "", 0, "a\nz\n")
+	testRun(t, []string{"vars", "true"}, "", 0, "")
+}
+
+func TestCheck(t *testing.T) {
+	testRun(t, []string{"check", "a & b"}, "", 0, "")
+	testRun(t, []string{"check", "-v"}, "a", 0, "ok\n")
+	testRun(t, []string{"check", "a &"}, "", 1, "")
+	testRun(t, []string{"check"}, "", 1, "")
+}
+
+func TestREPL(t *testing.T) {
+	testRun(t, []string{"repl", "-history", ""},
+		"let a = true\nlet b = a & false\n\na & (b |\n!b)\n:ast !a\n:vars\n:unset a\na\nlet or = a\n:nothing\n:quit\na\n", 0,
+		"boolexpr repl, enter :help for help\n"+
+			"> a = true\n"+
+			"> b = false\n"+
+			"> > . true\n"+
+			"> !\n  'a'\n"+
+			"> a = true\nb = false\n"+
+			"> > error: unbound variables: a\n"+
+			"> error: expected let <name> = <expression>\n"+
+			"> error: unknown command :nothing, enter :help for help\n"+
+			"> ")
+	testRun(t, []string{"repl", "-history", ""}, ":table !a\n(a", 0,
+		"boolexpr repl, enter :help for help\n"+
+			"> a  !a\n-  --\n0  1\n1  0\n"+
+			"> . \n")
+	testRun(t, []string{"repl", "-history", ""}, "a &\n", 0,
+		"boolexpr repl, enter :help for help\n"+
+			"> error: 1:4: unexpected end of input, expected \"!\", \"not\", \"true\", \"false\", identifier or \"(\"\n"+
+			"a &\n   ^\n"+
+			"> \n")
+	testRun(t, []string{"repl", "a"}, "", 2, "")
+}
+
+func TestREPLHistory(t *testing.T) {
+	var file = filepath.Join(t.TempDir(), "history")
+	testRun(t, []string{"repl", "-history", file}, "let a = true\n(a\n)\n", 0,
+		"boolexpr repl, enter :help for help\n> a = true\n> . true\n> \n")
+	testRun(t, []string{"repl", "-history", file}, ":history\n", 0,
+		"boolexpr repl, enter :help for help\n"+
+			">    1  let a = true\n"+
+			"   2  (a )\n"+
+			"   3  :history\n"+
+			"> \n")
+	var data, err = os.ReadFile(file)
+	if err != nil || string(data) != "let a = true\n(a )\n:history\n" {
+		t.Errorf("History file failed! Expected the inputs but got wrong result %q (%v) !", data, err)
+	}
+}
+
+func TestLSP(t *testing.T) {
+	var exit = `{"jsonrpc":"2.0","method":"exit"}`
+	testRun(t, []string{"lsp"}, "Content-Length: 33\r\n\r\n"+exit, 0, "")
+	testRun(t, []string{"lsp", "-schema", filepath.Join(t.TempDir(), "missing.json")}, "", 1, "")
+	testRun(t, []string{"lsp", "a"}, "", 2, "")
+}
+
+func TestREPLRerun(t *testing.T) {
+	testRun(t, []string{"repl", "-history", ""},
+		"let a = true\n!a\nlet a = false\n:history 2\n:history 7\n:history 5\n:unset b\n:unset\n:history\n", 0,
+		"boolexpr repl, enter :help for help\n"+
+			"> a = true\n"+
+			"> false\n"+
+			"> a = false\n"+
+			"> !a\ntrue\n"+
+			"> error: expected :history <n> with n from 1 to 4\n"+
+			"> error: :history can't run :history\n"+
+			"> error: expected :unset <name> of a variable that is set\n"+
+			"> error: expected :unset <name> of a variable that is set\n"+
+			">    1  let a = true\n"+
+			"   2  !a\n"+
+			"   3  let a = false\n"+
+			"   4  !a\n"+
+			"   5  :history 7\n"+
+			"   6  :history 5\n"+
+			"   7  :unset b\n"+
+			"   8  :unset\n"+
+			"   9  :history\n"+
+			"> \n")
+}
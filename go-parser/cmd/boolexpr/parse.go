@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/codec"
+)
+
+// runParse prints the parse tree of an expression.
+func runParse(args []string, env *environment) int {
+	var flags = flag.NewFlagSet("parse", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	var formatName = flags.String("format", "tree", "output `format`: tree, ast or json")
+	var spans = flags.Bool("spans", false, "add the lines and columns of the nodes")
+	flags.Usage = func() {
+		fmt.Fprintln(env.stderr, "usage: boolexpr parse [flags] [expression]")
+		fmt.Fprintln(env.stderr, "\nReads the expression from stdin if there is no argument.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *formatName != "tree" && *formatName != "ast" && *formatName != "json" {
+		fmt.Fprintf(env.stderr, "boolexpr: unknown format %q, use tree, ast or json\n", *formatName)
+		return 2
+	}
+	var text, ok = readText(flags.Args(), env)
+	if !ok {
+		return 1
+	}
+	var node ast.Node
+	var err error
+	if *spans {
+		node, err = boolparser.ParseWithSpans(text)
+	} else {
+		node, err = boolparser.Parse(text)
+	}
+	if err != nil {
+		fmt.Fprintf(env.stderr, "boolexpr: %v\n", err)
+		return 1
+	}
+	switch *formatName {
+	case "ast":
+		fmt.Fprintln(env.stdout, node)
+	case "json":
+		var encoded, err = codec.MarshalJSON(node)
+		if err != nil {
+			fmt.Fprintf(env.stderr, "boolexpr: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(env.stdout, "%s\n", encoded)
+	default:
+		writeTree(env.stdout, node, 0)
+	}
+	return 0
+}
+
+// writeTree writes one line for every node of the tree, indented by its
+// depth. The span of a node follows its operator.
+func writeTree(writer io.Writer, node ast.Node, depth int) {
+	var line = strings.Repeat("  ", depth) + ast.Label(node)
+	if spanned, ok := node.(ast.Spanned); ok {
+		var span = spanned.Location
+		line += fmt.Sprintf("  %d:%d-%d:%d", span.Start.Line, span.Start.Column, span.End.Line, span.End.Column)
+		node = spanned.Ex
+	}
+	fmt.Fprintln(writer, line)
+	for _, operand := range ast.Operands(node) {
+		writeTree(writer, operand, depth+1)
+	}
+}
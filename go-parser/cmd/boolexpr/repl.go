@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/truthtable"
+)
+
+// maxHistory is the number of inputs that are kept in the history file.
+const maxHistory = 1000
+
+// replHelp describes the input of the REPL.
+const replHelp = `Enter an expression to evaluate it with the variables set by let.
+Input with unbalanced parentheses continues on the next line.
+
+  let <name> = <expression>  set a variable to the value of the expression
+  :ast <expression>          print the parse tree
+  :table <expression>        print the truth table
+  :vars                      print the variables that are set
+  :unset <name>              remove a variable
+  :history                   print the previous inputs
+  :history <n>               run the n-th input again
+  :help                      print this help
+  :quit                      leave the REPL, like the end of the input
+`
+
+// repl is the state of an interactive session.
+type repl struct {
+	env     *environment
+	vars    map[string]bool
+	history []string
+}
+
+// runREPL reads expressions and commands from stdin until the end of the
+// input and prints their results.
+func runREPL(args []string, env *environment) int {
+	var flags = flag.NewFlagSet("repl", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	var historyFile = flags.String("history", defaultHistoryFile(), "keep the history in the `file`, none if empty")
+	flags.Usage = func() {
+		fmt.Fprintln(env.stderr, "usage: boolexpr repl [flags]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+	var session = &repl{env: env, vars: make(map[string]bool)}
+	if *historyFile != "" {
+		if data, err := os.ReadFile(*historyFile); err == nil {
+			session.history = strings.FieldsFunc(string(data), func(r rune) bool { return r == '\n' })
+		}
+	}
+	fmt.Fprintln(env.stdout, "boolexpr repl, enter :help for help")
+	session.loop(bufio.NewScanner(env.stdin))
+	if *historyFile != "" {
+		if err := session.saveHistory(*historyFile); err != nil {
+			fmt.Fprintf(env.stderr, "boolexpr: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// defaultHistoryFile returns ~/.boolexpr_history or nothing if there is no
+// home directory.
+func defaultHistoryFile() string {
+	var home, err = os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".boolexpr_history")
+}
+
+// saveHistory writes the last inputs to the file. Inputs that span several
+// lines are joined by spaces.
+func (session *repl) saveHistory(file string) error {
+	var history = session.history
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	return os.WriteFile(file, []byte(strings.Join(history, "\n")+"\n"), 0o600)
+}
+
+// loop reads the inputs until the end of the input or :quit.
+func (session *repl) loop(scanner *bufio.Scanner) {
+	var input []string
+	fmt.Fprint(session.env.stdout, "> ")
+	for scanner.Scan() {
+		input = append(input, scanner.Text())
+		var text = strings.Join(input, "\n")
+		if strings.Count(text, "(") > strings.Count(text, ")") {
+			fmt.Fprint(session.env.stdout, ". ")
+			continue
+		}
+		input = nil
+		if strings.TrimSpace(text) != "" {
+			session.history = append(session.history, strings.Join(strings.Fields(text), " "))
+			if !session.execute(strings.TrimSpace(text)) {
+				return
+			}
+		}
+		fmt.Fprint(session.env.stdout, "> ")
+	}
+	fmt.Fprintln(session.env.stdout)
+}
+
+// execute runs a single input and returns false if the session ends.
+func (session *repl) execute(text string) bool {
+	var out = session.env.stdout
+	var command, argument = text, ""
+	if index := strings.IndexAny(text, " \t\n"); index >= 0 {
+		command, argument = text[:index], strings.TrimSpace(text[index:])
+	}
+	switch command {
+	case ":quit", ":q":
+		return false
+	case ":help":
+		fmt.Fprint(out, replHelp)
+	case ":history":
+		if argument != "" {
+			return session.rerun(argument)
+		}
+		for index, entry := range session.history {
+			fmt.Fprintf(out, "%4d  %s\n", index+1, entry)
+		}
+	case ":vars":
+		var names = make([]string, 0, len(session.vars))
+		for name := range session.vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(out, "%s = %t\n", name, session.vars[name])
+		}
+	case ":unset":
+		if _, found := session.vars[argument]; !found {
+			fmt.Fprintln(out, "error: expected :unset <name> of a variable that is set")
+		}
+		delete(session.vars, argument)
+	case ":ast":
+		if node, ok := session.parse(argument); ok {
+			writeTree(out, node, 0)
+		}
+	case ":table":
+		if node, ok := session.parse(argument); ok {
+			var table, err = truthtable.New(node, truthtable.Options{})
+			if err == nil {
+				err = table.Render(out, truthtable.Text)
+			}
+			if err != nil {
+				fmt.Fprintf(out, "error: %v\n", err)
+			}
+		}
+	case "let":
+		session.let(argument)
+	default:
+		if strings.HasPrefix(command, ":") {
+			fmt.Fprintf(out, "error: unknown command %s, enter :help for help\n", command)
+		} else if node, ok := session.parse(text); ok {
+			session.eval(node)
+		}
+	}
+	return true
+}
+
+// rerun runs the input with the number from the history again. Like a shell
+// it prints the input and replaces ":history <n>" by it in the history.
+func (session *repl) rerun(number string) bool {
+	var index, err = strconv.Atoi(number)
+	// The last entry is the :history command that is running.
+	if err != nil || index < 1 || index >= len(session.history) {
+		fmt.Fprintf(session.env.stdout, "error: expected :history <n> with n from 1 to %d\n", len(session.history)-1)
+		return true
+	}
+	var entry = session.history[index-1]
+	if strings.HasPrefix(entry, ":history ") {
+		fmt.Fprintln(session.env.stdout, "error: :history can't run :history")
+		return true
+	}
+	session.history[len(session.history)-1] = entry
+	fmt.Fprintln(session.env.stdout, entry)
+	return session.execute(entry)
+}
+
+// parse parses the expression and prints syntax errors.
+func (session *repl) parse(text string) (ast.Node, bool) {
+	var node, err = boolparser.Parse(text)
+	if err != nil {
+		fmt.Fprintf(session.env.stdout, "error: %v\n", err)
+		return nil, false
+	}
+	return node, true
+}
+
+// eval prints the value of the expression for the variables that are set.
+func (session *repl) eval(node ast.Node) {
+	var value, err = ast.EvalStrict(node, session.vars)
+	if err != nil {
+		fmt.Fprintf(session.env.stdout, "error: %v\n", err)
+		return
+	}
+	fmt.Fprintln(session.env.stdout, value)
+}
+
+// let sets a variable to the value of the expression in a binding like
+// "a = b & c".
+func (session *repl) let(binding string) {
+	var name, text, found = strings.Cut(binding, "=")
+	name = strings.TrimSpace(name)
+	// The name has to be an identifier that isn't a keyword, i. e. it has to
+	// parse as the variable itself.
+	var variable, err = boolparser.Parse(name)
+	if !found || err != nil || variable != (ast.Val{Name: name}) {
+		fmt.Fprintln(session.env.stdout, "error: expected let <name> = <expression>")
+		return
+	}
+	var node, ok = session.parse(text)
+	if !ok {
+		return
+	}
+	value, err := ast.EvalStrict(node, session.vars)
+	if err != nil {
+		fmt.Fprintf(session.env.stdout, "error: %v\n", err)
+		return
+	}
+	session.vars[name] = value
+	fmt.Fprintf(session.env.stdout, "%s = %t\n", name, value)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/truthtable"
+)
+
+// runTable prints the truth table of an expression.
+func runTable(args []string, env *environment) int {
+	var flags = flag.NewFlagSet("table", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	var formatName = flags.String("format", "text", "output `format`: text, markdown, csv or json")
+	var subExpressions = flags.Bool("sub", false, "add a column for every sub-expression")
+	var maxVars = flags.Int("max-vars", truthtable.DefaultMaxVars, fmt.Sprintf("maximum `number` of variables, at most %d", truthtable.MaxVarsLimit))
+	flags.Usage = func() {
+		fmt.Fprintln(env.stderr, "usage: boolexpr table [flags] [expression]")
+		fmt.Fprintln(env.stderr, "\nReads the expression from stdin if there is no argument.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	var format, err = truthtable.ParseFormat(*formatName)
+	if err != nil {
+		fmt.Fprintf(env.stderr, "boolexpr: %v\n", err)
+		return 2
+	}
+	var node, ok = readExpression(flags.Args(), env)
+	if !ok {
+		return 1
+	}
+	table, err := truthtable.New(node, truthtable.Options{MaxVars: *maxVars, SubExpressions: *subExpressions})
+	if err == nil {
+		err = table.Render(env.stdout, format)
+	}
+	if err != nil {
+		fmt.Fprintf(env.stderr, "boolexpr: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+)
+
+// runVars prints the variables of an expression in alphabetical order, one
+// per line.
+func runVars(args []string, env *environment) int {
+	var flags = flag.NewFlagSet("vars", flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(env.stderr, "usage: boolexpr vars [expression]")
+		fmt.Fprintln(env.stderr, "\nReads the expression from stdin if there is no argument.")
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	var node, ok = readExpression(flags.Args(), env)
+	if !ok {
+		return 1
+	}
+	for _, name := range ast.Vars(node) {
+		fmt.Fprintln(env.stdout, name)
+	}
+	return 0
+}
@@ -0,0 +1,262 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+)
+
+// binaryMagic starts the binary encoding. The Version follows it.
+const binaryMagic = "BX"
+
+// Tags of the nodes in the binary encoding. A tag for an operator is followed
+// by its operands, the tag of a variable by the index of its name.
+const (
+	tagFalse byte = iota
+	tagTrue
+	tagVar
+	tagNot
+	tagOr
+	tagAnd
+	tagXor
+	tagImplies
+	tagEquiv
+
+	// tagSpanned is followed by the offsets, lines and columns of the start
+	// and the end of the span and the node that it wraps.
+	tagSpanned
+)
+
+// maxBinaryDepth limits the nesting of the nodes that UnmarshalBinary
+// decodes, so that corrupt data can't overflow the stack. It's the limit that
+// encoding/json puts on the JSON encoding.
+const maxBinaryDepth = 10000
+
+// binaryTypes are the node types of the JSON encoding for the tags of infix
+// operators.
+var binaryTypes = map[byte]string{tagOr: typeOr, tagAnd: typeAnd, tagXor: typeXor,
+	tagImplies: typeImplies, tagEquiv: typeEquiv}
+
+// MarshalBinary encodes the expression in a compact binary format: the magic
+// "BX" and the Version are followed by the names of the variables, each one
+// written once, and the nodes in prefix order as one byte per operator and
+// constant. Integers are written as varints.
+func MarshalBinary(node ast.Node) ([]byte, error) {
+	var encoder = binaryEncoder{indexes: make(map[string]int)}
+	var body bytes.Buffer
+	if err := encoder.encode(&body, node); err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	buffer.WriteString(binaryMagic)
+	buffer.WriteByte(Version)
+	writeUvarint(&buffer, len(encoder.names))
+	for _, name := range encoder.names {
+		writeUvarint(&buffer, len(name))
+		buffer.WriteString(name)
+	}
+	buffer.Write(body.Bytes())
+	return buffer.Bytes(), nil
+}
+
+// binaryEncoder collects the names of the variables while it encodes the
+// nodes.
+type binaryEncoder struct {
+	names   []string
+	indexes map[string]int
+}
+
+// writeUvarint writes the non-negative integer as a varint.
+func writeUvarint(buffer *bytes.Buffer, value int) {
+	var encoded [binary.MaxVarintLen64]byte
+	buffer.Write(encoded[:binary.PutUvarint(encoded[:], uint64(value))])
+}
+
+// encode writes the nodes of the tree in prefix order.
+func (encoder *binaryEncoder) encode(buffer *bytes.Buffer, node ast.Node) error {
+	switch node := node.(type) {
+	case ast.Spanned:
+		buffer.WriteByte(tagSpanned)
+		var span = node.Location
+		for _, value := range []int{span.Start.Offset, span.Start.Line, span.Start.Column,
+			span.End.Offset, span.End.Line, span.End.Column} {
+			writeUvarint(buffer, value)
+		}
+		return encoder.encode(buffer, node.Ex)
+	case ast.Const:
+		if node.Value {
+			buffer.WriteByte(tagTrue)
+		} else {
+			buffer.WriteByte(tagFalse)
+		}
+		return nil
+	case ast.Val:
+		var index, found = encoder.indexes[node.Name]
+		if !found {
+			index = len(encoder.names)
+			encoder.names = append(encoder.names, node.Name)
+			encoder.indexes[node.Name] = index
+		}
+		buffer.WriteByte(tagVar)
+		writeUvarint(buffer, index)
+		return nil
+	case ast.Not:
+		buffer.WriteByte(tagNot)
+		return encoder.encode(buffer, node.Ex)
+	case ast.Or:
+		return encoder.encodeInfix(buffer, tagOr, node.LHS, node.RHS)
+	case ast.And:
+		return encoder.encodeInfix(buffer, tagAnd, node.LHS, node.RHS)
+	case ast.Xor:
+		return encoder.encodeInfix(buffer, tagXor, node.LHS, node.RHS)
+	case ast.Implies:
+		return encoder.encodeInfix(buffer, tagImplies, node.LHS, node.RHS)
+	case ast.Equiv:
+		return encoder.encodeInfix(buffer, tagEquiv, node.LHS, node.RHS)
+	}
+	return fmt.Errorf("codec: can't encode node %v of type %T", node, node)
+}
+
+// encodeInfix writes the tag of an infix operator and its operands.
+func (encoder *binaryEncoder) encodeInfix(buffer *bytes.Buffer, tag byte, lhs ast.Node, rhs ast.Node) error {
+	buffer.WriteByte(tag)
+	if err := encoder.encode(buffer, lhs); err != nil {
+		return err
+	}
+	return encoder.encode(buffer, rhs)
+}
+
+// UnmarshalBinary decodes an expression encoded by MarshalBinary. It fails
+// with ErrVersion or ErrInvalid if the data can't be decoded or if the nodes
+// are nested more than 10000 levels deep.
+func UnmarshalBinary(data []byte) (ast.Node, error) {
+	var reader = bytes.NewReader(data)
+	var magic = make([]byte, len(binaryMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || string(magic) != binaryMagic {
+		return nil, fmt.Errorf("%w: missing magic %q", ErrInvalid, binaryMagic)
+	}
+	var version, err = reader.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing version", ErrInvalid)
+	}
+	if version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrVersion, version)
+	}
+	var decoder = binaryDecoder{reader: reader}
+	var count = decoder.readLength()
+	for index := 0; index < count && decoder.err == nil; index++ {
+		var name = make([]byte, decoder.readLength())
+		if _, err := io.ReadFull(reader, name); err != nil {
+			decoder.fail("truncated name")
+		}
+		decoder.names = append(decoder.names, string(name))
+	}
+	var node = decoder.decode()
+	if decoder.err == nil && reader.Len() > 0 {
+		decoder.fail("trailing data")
+	}
+	if decoder.err != nil {
+		return nil, decoder.err
+	}
+	return node, nil
+}
+
+// binaryDecoder reads the nodes. It records the first error and returns
+// zero values after it.
+type binaryDecoder struct {
+	reader *bytes.Reader
+	names  []string
+	depth  int
+	err    error
+}
+
+// fail records an error unless there is one already.
+func (decoder *binaryDecoder) fail(reason string) {
+	if decoder.err == nil {
+		decoder.err = fmt.Errorf("%w: %s", ErrInvalid, reason)
+	}
+}
+
+// readInt reads a varint.
+func (decoder *binaryDecoder) readInt() int {
+	if decoder.err != nil {
+		return 0
+	}
+	var value, err = binary.ReadUvarint(decoder.reader)
+	if err != nil {
+		decoder.fail("truncated integer")
+		return 0
+	}
+	if value > math.MaxInt {
+		decoder.fail("integer out of range")
+		return 0
+	}
+	return int(value)
+}
+
+// readLength reads a varint that counts bytes or names. Each of them takes at
+// least one byte, so the count can't exceed the bytes that are left. This
+// keeps corrupt counts from allocating huge amounts of memory.
+func (decoder *binaryDecoder) readLength() int {
+	var length = decoder.readInt()
+	if length > decoder.reader.Len() {
+		decoder.fail("length out of range")
+		return 0
+	}
+	return length
+}
+
+// decode reads a node in prefix order. It keeps track of the depth of the
+// node.
+func (decoder *binaryDecoder) decode() ast.Node {
+	if decoder.err != nil {
+		return nil
+	}
+	var tag, err = decoder.reader.ReadByte()
+	if err != nil {
+		decoder.fail("truncated node")
+		return nil
+	}
+	if decoder.depth == maxBinaryDepth {
+		decoder.fail("nodes nested too deeply")
+		return nil
+	}
+	decoder.depth++
+	defer func() { decoder.depth-- }()
+	switch tag {
+	case tagFalse, tagTrue:
+		return ast.Const{Value: tag == tagTrue}
+	case tagVar:
+		var index = decoder.readInt()
+		if decoder.err == nil && index >= len(decoder.names) {
+			decoder.fail("unknown variable")
+		}
+		if decoder.err != nil {
+			return nil
+		}
+		return ast.Val{Name: decoder.names[index]}
+	case tagNot:
+		return ast.Not{Ex: decoder.decode()}
+	case tagSpanned:
+		var values [6]int
+		for index := range values {
+			values[index] = decoder.readInt()
+		}
+		return ast.Spanned{Ex: decoder.decode(), Location: ast.Span{
+			Start: ast.Position{Offset: values[0], Line: values[1], Column: values[2]},
+			End:   ast.Position{Offset: values[3], Line: values[4], Column: values[5]},
+		}}
+	}
+	var nodeType, found = binaryTypes[tag]
+	if !found {
+		decoder.fail(fmt.Sprintf("unknown tag %d", tag))
+		return nil
+	}
+	var lhs = decoder.decode()
+	var rhs = decoder.decode()
+	return binaryNode(nodeType, lhs, rhs)
+}
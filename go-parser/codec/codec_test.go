@@ -0,0 +1,136 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/internal/asttest"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	var node = boolparser.MustParse("!a & true -> b")
+	var expected = `{"version":1,"expression":{"type":"implies",` +
+		`"lhs":{"type":"and","lhs":{"type":"not","operand":{"type":"var","name":"a"}},"rhs":{"type":"const","value":true}},` +
+		`"rhs":{"type":"var","name":"b"}}}`
+	var result, err = MarshalJSON(node)
+	if err != nil || string(result) != expected {
+		t.Errorf("MarshalJSON on input \"%v\" failed! Expected %v but got wrong result %s (%v) !", node, expected, result, err)
+	}
+}
+
+// testRoundTrip checks that the JSON and the binary encoding decode to the
+// node again.
+func testRoundTrip(t *testing.T, node ast.Node) {
+	var encoded, err = MarshalJSON(node)
+	var result ast.Node
+	if err == nil {
+		result, err = UnmarshalJSON(encoded)
+	}
+	if err != nil || !reflect.DeepEqual(result, node) {
+		t.Fatalf("JSON round trip on input \"%v\" failed! Expected %#v but got wrong result %#v (%v) !", node, node, result, err)
+	}
+	encoded, err = MarshalBinary(node)
+	if err == nil {
+		result, err = UnmarshalBinary(encoded)
+	}
+	if err != nil || !reflect.DeepEqual(result, node) {
+		t.Fatalf("Binary round trip on input \"%v\" failed! Expected %#v but got wrong result %#v (%v) !", node, node, result, err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var random = rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		testRoundTrip(t, asttest.RandomNode(random, 5))
+	}
+	for _, text := range []string{"a &\n  !(b | false)", strings.Repeat(" ", 200) + "a & b",
+		strings.Repeat("\n", 300) + strings.Repeat("(", 100) + "a" + strings.Repeat(")", 100)} {
+		var node, err = boolparser.ParseWithSpans(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testRoundTrip(t, node)
+	}
+	testRoundTrip(t, ast.Spanned{Ex: ast.Val{Name: "a"}, Location: ast.Span{
+		Start: ast.Position{Offset: 1 << 40, Line: 1 << 20, Column: 1},
+		End:   ast.Position{Offset: 1<<40 + 1, Line: 1 << 20, Column: 2},
+	}})
+}
+
+func TestMarshalBinary(t *testing.T) {
+	var node = boolparser.MustParse("a & !a | b")
+	var expected = []byte{'B', 'X', 1, 2, 1, 'a', 1, 'b', tagOr, tagAnd, tagVar, 0, tagNot, tagVar, 0, tagVar, 1}
+	var result, err = MarshalBinary(node)
+	if err != nil || !reflect.DeepEqual(result, expected) {
+		t.Errorf("MarshalBinary on input \"%v\" failed! Expected %v but got wrong result %v (%v) !", node, expected, result, err)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for text, expected := range map[string]error{
+		`{"version":2,"expression":{"type":"var","name":"a"}}`: ErrVersion,
+		`{"version":1,"expression":{"type":"nand"}}`:           ErrInvalid,
+		`{"version":1,"expression":{"type":"not"}}`:            ErrInvalid,
+		`{"version":1,"expression":{"type":"var"}}`:            ErrInvalid,
+		`{"version":1}`: ErrInvalid,
+		`[1]`:           ErrInvalid,
+	} {
+		if _, err := UnmarshalJSON([]byte(text)); !errors.Is(err, expected) {
+			t.Errorf("UnmarshalJSON on input \"%v\" failed! Expected %v but got wrong result %v !", text, expected, err)
+		}
+	}
+	for _, test := range []struct {
+		data     []byte
+		expected error
+	}{
+		{[]byte("BX"), ErrInvalid},
+		{[]byte{'B', 'X', 2, 0, tagTrue}, ErrVersion},
+		{[]byte{'X', 'B', 1, 0, tagTrue}, ErrInvalid},
+		{[]byte{'B', 'X', 1, 0, tagAnd, tagTrue}, ErrInvalid},
+		{[]byte{'B', 'X', 1, 0, tagVar, 0}, ErrInvalid},
+		{[]byte{'B', 'X', 1, 0, 42}, ErrInvalid},
+		{[]byte{'B', 'X', 1, 0, tagTrue, tagTrue}, ErrInvalid},
+		{[]byte{'B', 'X', 1, 1, 200, 'a'}, ErrInvalid},
+	} {
+		if _, err := UnmarshalBinary(test.data); !errors.Is(err, test.expected) {
+			t.Errorf("UnmarshalBinary on input \"%v\" failed! Expected %v but got wrong result %v !", test.data, test.expected, err)
+		}
+	}
+}
+
+func TestUnmarshalBinaryDepth(t *testing.T) {
+	var deep = append([]byte{'B', 'X', 1, 0}, bytes.Repeat([]byte{tagNot}, 1<<24)...)
+	if _, err := UnmarshalBinary(append(deep, tagTrue)); !errors.Is(err, ErrInvalid) {
+		t.Errorf("UnmarshalBinary on %d nested nots failed! Expected %v but got wrong result %v !", 1<<24, ErrInvalid, err)
+	}
+	var node ast.Node = ast.Const{Value: true}
+	for depth := 1; depth < maxBinaryDepth; depth++ {
+		node = ast.Not{Ex: node}
+	}
+	var encoded, _ = MarshalBinary(node)
+	if result, err := UnmarshalBinary(encoded); err != nil || !reflect.DeepEqual(result, node) {
+		t.Errorf("UnmarshalBinary on %d nested nodes failed! Expected the nodes but got wrong result %v !", maxBinaryDepth, err)
+	}
+}
+
+func TestExpression(t *testing.T) {
+	type document struct {
+		Rule Expression `json:"rule"`
+	}
+	var doc = document{Expression{boolparser.MustParse("a <-> false")}}
+	var encoded, err = json.Marshal(doc)
+	var result document
+	if err == nil {
+		err = json.Unmarshal(encoded, &result)
+	}
+	if err != nil || !reflect.DeepEqual(result, doc) {
+		t.Errorf("Expression on input \"%v\" failed! Expected %v but got wrong result %v (%v) !", doc, doc, result, err)
+	}
+}
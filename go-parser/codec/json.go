@@ -0,0 +1,220 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+)
+
+// Version is the version of the encodings written by this package. Decoding
+// fails for other versions.
+const Version = 1
+
+// ErrVersion is returned when decoding data of an unsupported Version.
+var ErrVersion = errors.New("unsupported encoding version")
+
+// ErrInvalid is returned when decoding data that isn't an expression.
+var ErrInvalid = errors.New("invalid encoded expression")
+
+// Node types of the JSON encoding.
+const (
+	typeOr      = "or"
+	typeAnd     = "and"
+	typeXor     = "xor"
+	typeImplies = "implies"
+	typeEquiv   = "equiv"
+	typeNot     = "not"
+	typeVar     = "var"
+	typeConst   = "const"
+)
+
+// jsonDocument is the top level of the JSON encoding.
+type jsonDocument struct {
+	Version    int       `json:"version"`
+	Expression *jsonNode `json:"expression"`
+}
+
+// jsonNode is a node of the JSON encoding. Type tells which of the other
+// fields are set.
+type jsonNode struct {
+	Type    string    `json:"type"`
+	Name    string    `json:"name,omitempty"`
+	Value   *bool     `json:"value,omitempty"`
+	Operand *jsonNode `json:"operand,omitempty"`
+	LHS     *jsonNode `json:"lhs,omitempty"`
+	RHS     *jsonNode `json:"rhs,omitempty"`
+	Span    *jsonSpan `json:"span,omitempty"`
+}
+
+// jsonSpan is the encoding of an ast.Span.
+type jsonSpan struct {
+	Start jsonPosition `json:"start"`
+	End   jsonPosition `json:"end"`
+}
+
+// jsonPosition is the encoding of an ast.Position.
+type jsonPosition struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// MarshalJSON encodes the expression as JSON, e.g. "a & !b" as
+//
+//	{"version":1,"expression":{"type":"and","lhs":{"type":"var","name":"a"},
+//	"rhs":{"type":"not","operand":{"type":"var","name":"b"}}}}
+//
+// The span of an ast.Spanned becomes the "span" of the node it wraps.
+func MarshalJSON(node ast.Node) ([]byte, error) {
+	var encoded, err = encodeJSON(node)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonDocument{Version, encoded})
+}
+
+// UnmarshalJSON decodes an expression encoded by MarshalJSON. It fails with
+// ErrVersion or ErrInvalid if the data can't be decoded.
+func UnmarshalJSON(data []byte) (ast.Node, error) {
+	var document jsonDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if document.Version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrVersion, document.Version)
+	}
+	return decodeJSON(document.Expression)
+}
+
+// encodeJSON converts the node to its JSON encoding.
+func encodeJSON(node ast.Node) (*jsonNode, error) {
+	var binary = func(nodeType string, lhs ast.Node, rhs ast.Node) (*jsonNode, error) {
+		var left, err = encodeJSON(lhs)
+		if err != nil {
+			return nil, err
+		}
+		right, err := encodeJSON(rhs)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Type: nodeType, LHS: left, RHS: right}, nil
+	}
+	switch node := node.(type) {
+	case ast.Spanned:
+		var encoded, err = encodeJSON(node.Ex)
+		if err != nil {
+			return nil, err
+		}
+		var start, end = node.Location.Start, node.Location.End
+		encoded.Span = &jsonSpan{
+			jsonPosition{start.Offset, start.Line, start.Column},
+			jsonPosition{end.Offset, end.Line, end.Column},
+		}
+		return encoded, nil
+	case ast.Or:
+		return binary(typeOr, node.LHS, node.RHS)
+	case ast.And:
+		return binary(typeAnd, node.LHS, node.RHS)
+	case ast.Xor:
+		return binary(typeXor, node.LHS, node.RHS)
+	case ast.Implies:
+		return binary(typeImplies, node.LHS, node.RHS)
+	case ast.Equiv:
+		return binary(typeEquiv, node.LHS, node.RHS)
+	case ast.Not:
+		var operand, err = encodeJSON(node.Ex)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonNode{Type: typeNot, Operand: operand}, nil
+	case ast.Val:
+		return &jsonNode{Type: typeVar, Name: node.Name}, nil
+	case ast.Const:
+		var value = node.Value
+		return &jsonNode{Type: typeConst, Value: &value}, nil
+	}
+	return nil, fmt.Errorf("codec: can't encode node %v of type %T", node, node)
+}
+
+// decodeJSON converts the JSON encoding of a node to the node.
+func decodeJSON(encoded *jsonNode) (ast.Node, error) {
+	if encoded == nil {
+		return nil, fmt.Errorf("%w: missing node", ErrInvalid)
+	}
+	var node ast.Node
+	var err error
+	switch encoded.Type {
+	case typeOr, typeAnd, typeXor, typeImplies, typeEquiv:
+		var lhs, rhs ast.Node
+		if lhs, err = decodeJSON(encoded.LHS); err != nil {
+			return nil, err
+		}
+		if rhs, err = decodeJSON(encoded.RHS); err != nil {
+			return nil, err
+		}
+		node = binaryNode(encoded.Type, lhs, rhs)
+	case typeNot:
+		var operand ast.Node
+		if operand, err = decodeJSON(encoded.Operand); err != nil {
+			return nil, err
+		}
+		node = ast.Not{Ex: operand}
+	case typeVar:
+		if encoded.Name == "" {
+			return nil, fmt.Errorf("%w: variable without name", ErrInvalid)
+		}
+		node = ast.Val{Name: encoded.Name}
+	case typeConst:
+		if encoded.Value == nil {
+			return nil, fmt.Errorf("%w: constant without value", ErrInvalid)
+		}
+		node = ast.Const{Value: *encoded.Value}
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalid, encoded.Type)
+	}
+	if span := encoded.Span; span != nil {
+		node = ast.Spanned{Ex: node, Location: ast.Span{
+			Start: ast.Position{Offset: span.Start.Offset, Line: span.Start.Line, Column: span.Start.Column},
+			End:   ast.Position{Offset: span.End.Offset, Line: span.End.Line, Column: span.End.Column},
+		}}
+	}
+	return node, nil
+}
+
+// binaryNode creates the node of the type for an infix operator.
+func binaryNode(nodeType string, lhs ast.Node, rhs ast.Node) ast.Node {
+	switch nodeType {
+	case typeOr:
+		return ast.Or{LHS: lhs, RHS: rhs}
+	case typeAnd:
+		return ast.And{LHS: lhs, RHS: rhs}
+	case typeXor:
+		return ast.Xor{LHS: lhs, RHS: rhs}
+	case typeImplies:
+		return ast.Implies{LHS: lhs, RHS: rhs}
+	}
+	return ast.Equiv{LHS: lhs, RHS: rhs}
+}
+
+// Expression wraps an ast.Node so that it can be a field of structs that are
+// encoded by encoding/json. It uses the encoding of MarshalJSON.
+type Expression struct {
+	ast.Node
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (expression Expression) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(expression.Node)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (expression *Expression) UnmarshalJSON(data []byte) error {
+	var node, err = UnmarshalJSON(data)
+	if err != nil {
+		return err
+	}
+	expression.Node = node
+	return nil
+}
@@ -0,0 +1,251 @@
+package compile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+)
+
+// opcode is the operation of an instruction.
+type opcode uint8
+
+const (
+
+	// opLoad sets the accumulator to the value of the variable in slot arg.
+	opLoad opcode = iota
+
+	// opConst sets the accumulator to true if arg is 1 and to false otherwise.
+	opConst
+
+	// opNot negates the accumulator.
+	opNot
+
+	// opJumpIfTrue continues at instruction arg if the accumulator is true.
+	opJumpIfTrue
+
+	// opJumpIfFalse continues at instruction arg if the accumulator is false.
+	opJumpIfFalse
+
+	// opPush pushes the accumulator onto the stack.
+	opPush
+
+	// opXor sets the accumulator to the popped value xor the accumulator.
+	opXor
+
+	// opEquiv sets the accumulator to true if the popped value equals the
+	// accumulator.
+	opEquiv
+)
+
+// opcodeNames are the names of the opcodes for Program.String.
+var opcodeNames = [...]string{"load", "const", "not", "jump-if-true", "jump-if-false", "push", "xor", "equiv"}
+
+// instruction is an opcode along with its argument.
+type instruction struct {
+	op  opcode
+	arg int
+}
+
+// Program is an expression compiled to bytecode. It evaluates the expression
+// without looking up the variables by name: every variable has a slot, i. e.
+// an index into the values passed to Eval or EvalBits. The bytecode works on a
+// single boolean accumulator and evaluates And, Or and Implies with short
+// circuits. Only Xor and Equiv need a stack for the value of their LHS.
+// A Program may be used by several goroutines at once.
+type Program struct {
+
+	// Vars are the names of the variables by slot in sorted order.
+	Vars []string
+
+	code []instruction
+}
+
+// Compile compiles the expression to a Program.
+func Compile(node ast.Node) *Program {
+	var vars = ast.Vars(node)
+	var compiler = compiler{slots: make(map[string]int)}
+	for slot, name := range vars {
+		compiler.slots[name] = slot
+	}
+	compiler.compile(node)
+	return &Program{Vars: vars, code: compiler.code}
+}
+
+// compiler holds the state of Compile.
+type compiler struct {
+	slots map[string]int
+	code  []instruction
+}
+
+// emit appends an instruction and returns its index.
+func (c *compiler) emit(op opcode, arg int) int {
+	c.code = append(c.code, instruction{op, arg})
+	return len(c.code) - 1
+}
+
+// compile appends the code that leaves the value of the node in the
+// accumulator.
+func (c *compiler) compile(node ast.Node) {
+	switch node := node.(type) {
+	case ast.Spanned:
+		c.compile(node.Ex)
+	case ast.Val:
+		c.emit(opLoad, c.slots[node.Name])
+	case ast.Const:
+		if node.Value {
+			c.emit(opConst, 1)
+		} else {
+			c.emit(opConst, 0)
+		}
+	case ast.Not:
+		c.compile(node.Ex)
+		c.emit(opNot, 0)
+	case ast.And:
+		c.shortCircuit(node.LHS, node.RHS, opJumpIfFalse, false)
+	case ast.Or:
+		c.shortCircuit(node.LHS, node.RHS, opJumpIfTrue, false)
+	case ast.Implies:
+		// a -> b is !a | b.
+		c.shortCircuit(node.LHS, node.RHS, opJumpIfTrue, true)
+	case ast.Xor:
+		c.binary(node.LHS, node.RHS, opXor)
+	case ast.Equiv:
+		c.binary(node.LHS, node.RHS, opEquiv)
+	default:
+		panic(fmt.Sprintf("compile: unknown node %v", node))
+	}
+}
+
+// shortCircuit appends the code for an operator that skips the rhs if the jump
+// is taken after the lhs. If negate is true the lhs is negated first.
+func (c *compiler) shortCircuit(lhs ast.Node, rhs ast.Node, jump opcode, negate bool) {
+	c.compile(lhs)
+	if negate {
+		c.emit(opNot, 0)
+	}
+	var index = c.emit(jump, 0)
+	c.compile(rhs)
+	c.code[index].arg = len(c.code)
+}
+
+// binary appends the code for an operator that needs the values of both
+// operands.
+func (c *compiler) binary(lhs ast.Node, rhs ast.Node, op opcode) {
+	c.compile(lhs)
+	c.emit(opPush, 0)
+	c.compile(rhs)
+	c.emit(op, 0)
+}
+
+// Slot returns the slot of the variable with the name. It returns false if
+// the expression has no such variable.
+func (p *Program) Slot(name string) (int, bool) {
+	for slot, other := range p.Vars {
+		if other == name {
+			return slot, true
+		}
+	}
+	return 0, false
+}
+
+// Values converts the vars map of ast.Node.Eval to the values for Eval.
+// Missing vars are false.
+func (p *Program) Values(vars map[string]bool) []bool {
+	var values = make([]bool, len(p.Vars))
+	for slot, name := range p.Vars {
+		values[slot] = vars[name]
+	}
+	return values
+}
+
+// Eval evaluates the expression with the values of the variables by slot.
+// values must have a value for every slot.
+func (p *Program) Eval(values []bool) bool {
+	return p.run(values, nil)
+}
+
+// Bitset holds the values of the variables by slot as bits: the value of slot
+// i is bit i % 64 of element i / 64.
+type Bitset []uint64
+
+// NewBitset creates a Bitset with room for size slots.
+func NewBitset(size int) Bitset {
+	return make(Bitset, (size+63)/64)
+}
+
+// Set sets the value of the slot.
+func (bits Bitset) Set(slot int, value bool) {
+	if value {
+		bits[slot/64] |= 1 << (slot % 64)
+	} else {
+		bits[slot/64] &^= 1 << (slot % 64)
+	}
+}
+
+// Get returns the value of the slot.
+func (bits Bitset) Get(slot int) bool {
+	return bits[slot/64]&(1<<(slot%64)) != 0
+}
+
+// EvalBits evaluates the expression with the values of the variables in a
+// Bitset. bits must have room for every slot.
+func (p *Program) EvalBits(bits Bitset) bool {
+	return p.run(nil, bits)
+}
+
+// run executes the bytecode. The values of the variables come from values
+// unless it's nil, otherwise they come from bits.
+func (p *Program) run(values []bool, bits Bitset) bool {
+	var buffer [16]bool
+	var stack = buffer[:0]
+	var accumulator bool
+	for pc := 0; pc < len(p.code); pc++ {
+		var instruction = p.code[pc]
+		switch instruction.op {
+		case opLoad:
+			if values != nil {
+				accumulator = values[instruction.arg]
+			} else {
+				accumulator = bits.Get(instruction.arg)
+			}
+		case opConst:
+			accumulator = instruction.arg == 1
+		case opNot:
+			accumulator = !accumulator
+		case opJumpIfTrue:
+			if accumulator {
+				pc = instruction.arg - 1
+			}
+		case opJumpIfFalse:
+			if !accumulator {
+				pc = instruction.arg - 1
+			}
+		case opPush:
+			stack = append(stack, accumulator)
+		case opXor:
+			accumulator = stack[len(stack)-1] != accumulator
+			stack = stack[:len(stack)-1]
+		case opEquiv:
+			accumulator = stack[len(stack)-1] == accumulator
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return accumulator
+}
+
+// String returns the bytecode with one instruction per line for debugging.
+func (p *Program) String() string {
+	var builder strings.Builder
+	for index, instruction := range p.code {
+		fmt.Fprintf(&builder, "%3d %s", index, opcodeNames[instruction.op])
+		switch instruction.op {
+		case opLoad:
+			fmt.Fprintf(&builder, " %d (%s)", instruction.arg, p.Vars[instruction.arg])
+		case opConst, opJumpIfTrue, opJumpIfFalse:
+			fmt.Fprintf(&builder, " %d", instruction.arg)
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
@@ -0,0 +1,97 @@
+package compile
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/internal/asttest"
+)
+
+func TestCompile(t *testing.T) {
+	for _, text := range []string{"a", "!a & b", "a | b & !c", "a -> b -> c", "a ^ b ^ (c <-> !d)",
+		"(a <-> (b ^ (c <-> (d ^ a)))) | false", "true & !(x -> false)"} {
+		var node = boolparser.MustParse(text)
+		var program = Compile(node)
+		for _, vars := range asttest.Assignments(program.Vars) {
+			var bits = NewBitset(len(program.Vars))
+			for slot, name := range program.Vars {
+				bits.Set(slot, vars[name])
+			}
+			var expected = node.Eval(vars)
+			if result := program.Eval(program.Values(vars)); result != expected {
+				t.Errorf("Eval on input \"%v\" failed for %v! Expected %v but got wrong result %v !\n%v",
+					text, vars, expected, result, program)
+			}
+			if result := program.EvalBits(bits); result != expected {
+				t.Errorf("EvalBits on input \"%v\" failed for %v! Expected %v but got wrong result %v !\n%v",
+					text, vars, expected, result, program)
+			}
+		}
+	}
+}
+
+func TestProgram(t *testing.T) {
+	var program = Compile(boolparser.MustParse("b & !a"))
+	var expected = "  0 load 1 (b)\n" +
+		"  1 jump-if-false 4\n" +
+		"  2 load 0 (a)\n" +
+		"  3 not\n"
+	if program.String() != expected {
+		t.Errorf("Compile on input \"b & !a\" failed! Expected\n%v\nbut got wrong result\n%v", expected, program)
+	}
+	if slot, found := program.Slot("b"); !found || slot != 1 {
+		t.Errorf("Slot of b must be 1 but got %v, %v !", slot, found)
+	}
+	if _, found := program.Slot("c"); found {
+		t.Errorf("Slot of c mustn't be found!")
+	}
+
+	var bits = NewBitset(130)
+	bits.Set(129, true)
+	bits.Set(3, true)
+	bits.Set(3, false)
+	if len(bits) != 3 || !bits.Get(129) || bits.Get(3) || bits.Get(128) {
+		t.Errorf("Bitset failed! Got %v !", bits)
+	}
+}
+
+// benchmarkExpression returns a rule with 32 variables that mixes all the
+// operators.
+func benchmarkExpression() ast.Node {
+	var parts []string
+	for index := 0; index < 8; index++ {
+		parts = append(parts, fmt.Sprintf("(v%d & !v%d | v%d ^ v%d)", 4*index, 4*index+1, 4*index+2, 4*index+3))
+	}
+	return boolparser.MustParse(strings.Join(parts, " -> "))
+}
+
+func BenchmarkEval(b *testing.B) {
+	var node = benchmarkExpression()
+	var program = Compile(node)
+	var vars = make(map[string]bool)
+	var bits = NewBitset(len(program.Vars))
+	for slot, name := range program.Vars {
+		vars[name] = slot%3 == 0
+		bits.Set(slot, vars[name])
+	}
+	var values = program.Values(vars)
+
+	b.Run("tree", func(b *testing.B) {
+		for index := 0; index < b.N; index++ {
+			node.Eval(vars)
+		}
+	})
+	b.Run("bytecode", func(b *testing.B) {
+		for index := 0; index < b.N; index++ {
+			program.Eval(values)
+		}
+	})
+	b.Run("bitset", func(b *testing.B) {
+		for index := 0; index < b.N; index++ {
+			program.EvalBits(bits)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package format
+
+import (
+	"fmt"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+)
+
+// Style configures the text created by Format.
+type Style struct {
+
+	// Keywords are written instead of the symbols "!", "&" and "|" unless
+	// they're empty. Parse the text with a boolparser.Parser for the same
+	// Keywords.
+	Keywords boolparser.Keywords
+
+	// Compact omits the spaces around the infix operators, e.g. "a&b|c".
+	// Keywords are surrounded by spaces nevertheless.
+	Compact bool
+}
+
+// DefaultStyle writes symbols with spaces around the infix operators, e.g.
+// "!a & (b | c)".
+var DefaultStyle = Style{}
+
+// KeywordStyle writes the boolparser.DefaultKeywords, e.g.
+// "not a and (b or c)".
+var KeywordStyle = Style{Keywords: boolparser.DefaultKeywords}
+
+// String formats the expression in the DefaultStyle.
+func String(node ast.Node) string {
+	return Format(node, DefaultStyle)
+}
+
+// Format returns the expression in the syntax of boolparser, i. e. the
+// result of parsing the text is the expression again. Format writes as few
+// parenthesis as the precedence and the associativity of the operators allow,
+// e.g. "a & (b | c)" but "a & b | c". Spans are ignored.
+//
+// The names of the variables are written as they are, so they have to be
+// identifiers that aren't keywords for the text to be parsed again.
+func Format(node ast.Node, style Style) string {
+	var formatter = formatter{style}
+	return formatter.format(node)
+}
+
+// formatter formats expressions in a Style.
+type formatter struct {
+	style Style
+}
+
+// precedence returns how tightly the operator of the node binds, see
+// boolparser. Variables and constants bind tightest.
+func precedence(node ast.Node) int {
+	switch node := node.(type) {
+	case ast.Spanned:
+		return precedence(node.Ex)
+	case ast.Equiv:
+		return 1
+	case ast.Implies:
+		return 2
+	case ast.Or:
+		return 3
+	case ast.Xor:
+		return 4
+	case ast.And:
+		return 5
+	case ast.Not:
+		return 6
+	}
+	return 7
+}
+
+// format returns the text of the node.
+func (f formatter) format(node ast.Node) string {
+	switch node := node.(type) {
+	case ast.Spanned:
+		return f.format(node.Ex)
+	case ast.Val:
+		return node.Name
+	case ast.Const:
+		if node.Value {
+			return "true"
+		}
+		return "false"
+	case ast.Not:
+		if f.style.Keywords.Not != "" {
+			return f.style.Keywords.Not + " " + f.operand(node.Ex, precedence(node))
+		}
+		return "!" + f.operand(node.Ex, precedence(node))
+	case ast.And:
+		return f.infix(node.LHS, f.symbol("&", f.style.Keywords.And), node.RHS, precedence(node), false)
+	case ast.Or:
+		return f.infix(node.LHS, f.symbol("|", f.style.Keywords.Or), node.RHS, precedence(node), false)
+	case ast.Xor:
+		return f.infix(node.LHS, f.symbol("^", ""), node.RHS, precedence(node), false)
+	case ast.Implies:
+		return f.infix(node.LHS, f.symbol("->", ""), node.RHS, precedence(node), true)
+	case ast.Equiv:
+		return f.infix(node.LHS, f.symbol("<->", ""), node.RHS, precedence(node), false)
+	}
+	panic(fmt.Sprintf("format: unknown node %v", node))
+}
+
+// symbol returns the operator surrounded by spaces unless the style is
+// compact. A keyword replaces the symbol unless it's empty.
+func (f formatter) symbol(symbol string, keyword string) string {
+	switch {
+	case keyword != "":
+		return " " + keyword + " "
+	case f.style.Compact:
+		return symbol
+	}
+	return " " + symbol + " "
+}
+
+// infix returns the text of an infix operator with the precedence. If
+// rightAssociative is false then a RHS with the same precedence needs
+// parenthesis, otherwise the LHS does.
+func (f formatter) infix(lhs ast.Node, symbol string, rhs ast.Node, precedence int,
+	rightAssociative bool) string {
+	var left, right = precedence, precedence + 1
+	if rightAssociative {
+		left, right = right, left
+	}
+	return f.operand(lhs, left) + symbol + f.operand(rhs, right)
+}
+
+// operand returns the text of the operand in parenthesis if it binds less
+// tightly than minPrecedence.
+func (f formatter) operand(node ast.Node, minPrecedence int) string {
+	if precedence(node) < minPrecedence {
+		return "(" + f.format(node) + ")"
+	}
+	return f.format(node)
+}
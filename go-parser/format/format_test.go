@@ -0,0 +1,54 @@
+package format
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/internal/asttest"
+)
+
+func TestFormat(t *testing.T) {
+	for _, text := range []string{"a & b | c", "a & (b | c)", "!(a ^ b) -> c -> d", "(a -> b) -> c",
+		"a <-> b <-> (c <-> d)", "!!a & true", "(a | b) ^ c & d", "!(a & false)"} {
+		var result = String(boolparser.MustParse(text))
+		if result != text {
+			t.Errorf("String on input \"%v\" failed! Got wrong result %v !", text, result)
+		}
+	}
+
+	var node = boolparser.MustParse("!a & (b | !(c -> d))")
+	var styles = map[string]Style{
+		"not a and (b or not (c -> d))": KeywordStyle,
+		"!a&(b|!(c->d))":                {Compact: true},
+		"!a und (b|!(c->d))":            {Keywords: boolparser.Keywords{And: "und"}, Compact: true},
+	}
+	for expected, style := range styles {
+		if result := Format(node, style); result != expected {
+			t.Errorf("Format with style %+v failed! Expected %v but got wrong result %v !", style, expected, result)
+		}
+	}
+
+	var spanned, _ = boolparser.ParseWithSpans("((a) & b)")
+	if result := String(spanned); result != "a & b" {
+		t.Errorf("String must ignore spans! Expected a & b but got %v !", result)
+	}
+}
+
+// TestRoundTrip checks the property Parse(Format(n)) == n for random
+// expressions in all styles.
+func TestRoundTrip(t *testing.T) {
+	var random = rand.New(rand.NewSource(1))
+	var styles = []Style{DefaultStyle, KeywordStyle, {Compact: true}, {Keywords: boolparser.DefaultKeywords, Compact: true}}
+	for count := 0; count < 500; count++ {
+		var node = asttest.RandomNode(random, 6)
+		for _, style := range styles {
+			var text = Format(node, style)
+			var result, err = boolparser.NewParser(style.Keywords).Parse(text)
+			if err != nil || result != node {
+				t.Fatalf("Parse(Format(%v)) with style %+v failed! Got %v from %q with error %v !",
+					node, style, result, text, err)
+			}
+		}
+	}
+}
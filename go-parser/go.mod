@@ -1,3 +1,3 @@
 module github.com/m-voit/concepts-of-programming-languages/go-parser
 
-go 1.15
+go 1.18
@@ -0,0 +1,174 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+)
+
+// Format is the output format of Write.
+type Format int
+
+const (
+
+	// DOT writes the tree in the DOT language of Graphviz, e.g. for
+	// "dot -Tsvg".
+	DOT Format = iota
+
+	// Mermaid writes the tree as a Mermaid flowchart, which e.g. GitHub renders
+	// in Markdown files.
+	Mermaid
+)
+
+// formatNames are the names of the formats for ParseFormat.
+var formatNames = map[string]Format{"dot": DOT, "mermaid": Mermaid}
+
+// ParseFormat returns the Format with the name "dot" or "mermaid".
+func ParseFormat(name string) (Format, error) {
+	var format, found = formatNames[strings.ToLower(name)]
+	if !found {
+		return DOT, fmt.Errorf("unknown format %q, use dot or mermaid", name)
+	}
+	return format, nil
+}
+
+// Options configure Write.
+type Options struct {
+
+	// Values are the values of the variables. If they are not nil, every node
+	// is labeled and coloured with its value in Kleene's logic: green if it is
+	// true, red if it is false and grey if it depends on unset variables.
+	Values map[string]bool
+}
+
+// colors are the fill colors of the values in DOT and Mermaid.
+var colors = map[ast.Truth]string{ast.True: "#a6e3a1", ast.False: "#f38ba8", ast.Unknown: "#d9d9d9"}
+
+// vertex is a node of the tree with its number in prefix order.
+type vertex struct {
+	id       int
+	label    string
+	value    ast.Truth
+	children []int
+}
+
+// vertices numbers the nodes of the tree in prefix order. Spans are skipped.
+// The value of a vertex is computed from the values of its children, so every
+// node is evaluated once.
+func vertices(node ast.Node, options Options) []vertex {
+	var result []vertex
+	var visit func(ast.Node) int
+	visit = func(node ast.Node) int {
+		if spanned, ok := node.(ast.Spanned); ok {
+			return visit(spanned.Ex)
+		}
+		var id = len(result)
+		result = append(result, vertex{id: id, label: label(node)})
+		var operands []ast.Truth
+		for _, operand := range ast.Operands(node) {
+			var child = visit(operand)
+			result[id].children = append(result[id].children, child)
+			operands = append(operands, result[child].value)
+		}
+		if options.Values != nil {
+			result[id].value = evalKleene(node, operands, options.Values)
+		}
+		return id
+	}
+	visit(node)
+	return result
+}
+
+// evalKleene returns the value of the node like ast.EvalKleene, but takes the
+// values of the operands of operators from operands.
+func evalKleene(node ast.Node, operands []ast.Truth, values map[string]bool) ast.Truth {
+	switch node.(type) {
+	case ast.Or:
+		return operands[0].Or(operands[1])
+	case ast.And:
+		return operands[0].And(operands[1])
+	case ast.Xor:
+		return operands[0].Xor(operands[1])
+	case ast.Implies:
+		return operands[0].Implies(operands[1])
+	case ast.Equiv:
+		return operands[0].Equiv(operands[1])
+	case ast.Not:
+		return operands[0].Not()
+	}
+	return ast.EvalKleene(node, values)
+}
+
+// label returns the operator of a node, the name of a variable or the value of
+// a constant.
+func label(node ast.Node) string {
+	if val, ok := node.(ast.Val); ok {
+		return val.Name
+	}
+	return ast.Label(node)
+}
+
+// Write writes the tree of the expression in the format to the writer. The
+// nodes are numbered n0, n1, ... in prefix order and the edges lead from the
+// operators to their operands from left to right.
+func Write(writer io.Writer, node ast.Node, format Format, options Options) error {
+	var lines []string
+	if format == Mermaid {
+		lines = mermaid(vertices(node, options), options.Values != nil)
+	} else {
+		lines = dot(vertices(node, options), options.Values != nil)
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dot returns the lines of the DOT graph.
+func dot(vertices []vertex, evaluated bool) []string {
+	var lines = []string{"digraph expression {", "  node [shape=box];"}
+	for _, vertex := range vertices {
+		if evaluated {
+			lines = append(lines, fmt.Sprintf("  n%d [label=%q, style=filled, fillcolor=%q];",
+				vertex.id, vertex.label+"\n"+vertex.value.String(), colors[vertex.value]))
+		} else {
+			lines = append(lines, fmt.Sprintf("  n%d [label=%q];", vertex.id, vertex.label))
+		}
+		for _, child := range vertex.children {
+			lines = append(lines, fmt.Sprintf("  n%d -> n%d;", vertex.id, child))
+		}
+	}
+	return append(lines, "}")
+}
+
+// mermaidEscapes replaces the characters that Mermaid would read as markup in
+// a label by entity codes.
+var mermaidEscapes = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+// mermaid returns the lines of the Mermaid flowchart.
+func mermaid(vertices []vertex, evaluated bool) []string {
+	var lines = []string{"flowchart TD"}
+	for _, vertex := range vertices {
+		var label = mermaidEscapes.Replace(vertex.label)
+		if evaluated {
+			label += "<br/>" + vertex.value.String()
+		}
+		lines = append(lines, fmt.Sprintf("  n%d[\"%s\"]", vertex.id, label))
+		for _, child := range vertex.children {
+			lines = append(lines, fmt.Sprintf("  n%d --> n%d", vertex.id, child))
+		}
+	}
+	if evaluated {
+		for _, value := range []ast.Truth{ast.True, ast.False, ast.Unknown} {
+			lines = append(lines, fmt.Sprintf("  classDef %vValue fill:%s", value, colors[value]))
+		}
+		for _, vertex := range vertices {
+			lines = append(lines, fmt.Sprintf("  class n%d %vValue", vertex.id, vertex.value))
+		}
+	}
+	return lines
+}
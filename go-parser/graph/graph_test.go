@@ -0,0 +1,96 @@
+package graph
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/internal/asttest"
+)
+
+func testWrite(t *testing.T, text string, format Format, options Options, expected string) {
+	var builder strings.Builder
+	if err := Write(&builder, boolparser.MustParse(text), format, options); err != nil || builder.String() != expected {
+		t.Errorf("Write on input \"%v\" failed! Expected\n%v\nbut got wrong result\n%v\n%v", text, expected, builder.String(), err)
+	}
+}
+
+func TestDOT(t *testing.T) {
+	testWrite(t, "a & !b", DOT, Options{},
+		"digraph expression {\n"+
+			"  node [shape=box];\n"+
+			"  n0 [label=\"&\"];\n"+
+			"  n0 -> n1;\n"+
+			"  n0 -> n2;\n"+
+			"  n1 [label=\"a\"];\n"+
+			"  n2 [label=\"!\"];\n"+
+			"  n2 -> n3;\n"+
+			"  n3 [label=\"b\"];\n"+
+			"}\n")
+	testWrite(t, "a | true", DOT, Options{Values: map[string]bool{}},
+		"digraph expression {\n"+
+			"  node [shape=box];\n"+
+			"  n0 [label=\"|\\ntrue\", style=filled, fillcolor=\"#a6e3a1\"];\n"+
+			"  n0 -> n1;\n"+
+			"  n0 -> n2;\n"+
+			"  n1 [label=\"a\\nunknown\", style=filled, fillcolor=\"#d9d9d9\"];\n"+
+			"  n2 [label=\"true\\ntrue\", style=filled, fillcolor=\"#a6e3a1\"];\n"+
+			"}\n")
+}
+
+func TestMermaid(t *testing.T) {
+	testWrite(t, "a <-> b", Mermaid, Options{},
+		"flowchart TD\n"+
+			"  n0[\"#lt;-#gt;\"]\n"+
+			"  n0 --> n1\n"+
+			"  n0 --> n2\n"+
+			"  n1[\"a\"]\n"+
+			"  n2[\"b\"]\n")
+	testWrite(t, "!a", Mermaid, Options{Values: map[string]bool{"a": false}},
+		"flowchart TD\n"+
+			"  n0[\"!<br/>true\"]\n"+
+			"  n0 --> n1\n"+
+			"  n1[\"a<br/>false\"]\n"+
+			"  classDef trueValue fill:#a6e3a1\n"+
+			"  classDef falseValue fill:#f38ba8\n"+
+			"  classDef unknownValue fill:#d9d9d9\n"+
+			"  class n0 trueValue\n"+
+			"  class n1 falseValue\n")
+}
+
+func TestSpans(t *testing.T) {
+	var spanned, err = boolparser.ParseWithSpans("a & b")
+	var plain, other strings.Builder
+	if err == nil {
+		err = Write(&plain, spanned, DOT, Options{})
+	}
+	if err == nil {
+		err = Write(&other, boolparser.MustParse("a & b"), DOT, Options{})
+	}
+	if err != nil || plain.String() != other.String() {
+		t.Errorf("Write on input \"%v\" failed! Expected\n%v\nbut got wrong result\n%v\n%v", spanned, other.String(), plain.String(), err)
+	}
+}
+
+func TestValues(t *testing.T) {
+	var random = rand.New(rand.NewSource(1))
+	var values = map[string]bool{"v0": true, "v1": false, "v2": true}
+	for i := 0; i < 200; i++ {
+		var node = asttest.RandomNode(random, 5)
+		var nodes []ast.Node
+		ast.Inspect(node, func(node ast.Node) bool {
+			if node != nil {
+				nodes = append(nodes, node)
+			}
+			return true
+		})
+		for index, vertex := range vertices(node, Options{Values: values}) {
+			if expected := ast.EvalKleene(nodes[index], values); vertex.value != expected {
+				t.Fatalf("vertices on input \"%v\" failed for %v! Expected %v but got wrong result %v !",
+					node, nodes[index], expected, vertex.value)
+			}
+		}
+	}
+}
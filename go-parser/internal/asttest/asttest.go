@@ -0,0 +1,47 @@
+package asttest
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+)
+
+// RandomNode creates a random expression with operators up to the depth. Its
+// variables are named v0 to v4.
+func RandomNode(random *rand.Rand, depth int) ast.Node {
+	if depth == 0 || random.Intn(4) == 0 {
+		if random.Intn(6) == 0 {
+			return ast.Const{Value: random.Intn(2) == 0}
+		}
+		return ast.Val{Name: fmt.Sprint("v", random.Intn(5))}
+	}
+	var lhs, rhs = RandomNode(random, depth-1), RandomNode(random, depth-1)
+	switch random.Intn(6) {
+	case 0:
+		return ast.Not{Ex: lhs}
+	case 1:
+		return ast.And{LHS: lhs, RHS: rhs}
+	case 2:
+		return ast.Or{LHS: lhs, RHS: rhs}
+	case 3:
+		return ast.Xor{LHS: lhs, RHS: rhs}
+	case 4:
+		return ast.Implies{LHS: lhs, RHS: rhs}
+	}
+	return ast.Equiv{LHS: lhs, RHS: rhs}
+}
+
+// Assignments returns all the combinations of values of the variables. In the
+// n-th combination the variable names[i] is true if bit i of n is set.
+func Assignments(names []string) []map[string]bool {
+	var result = make([]map[string]bool, 0, 1<<len(names))
+	for combination := 0; combination < 1<<len(names); combination++ {
+		var vars = make(map[string]bool, len(names))
+		for index, name := range names {
+			vars[name] = combination&(1<<index) != 0
+		}
+		result = append(result, vars)
+	}
+	return result
+}
@@ -0,0 +1,207 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Error codes of JSON-RPC and the Language Server Protocol.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// message is a JSON-RPC 2.0 request, notification or response. Requests have
+// an ID and a Method, notifications only a Method and responses only an ID.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+// responseError is the error of a failed request.
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// readMessage reads a message with a Content-Length header.
+func readMessage(reader *bufio.Reader) ([]byte, error) {
+	var header, err = textproto.NewReader(reader).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("lsp: invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	var body = make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// marshal encodes the value like json.Marshal but keeps the operators "&", "<"
+// and ">" readable.
+func marshal(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	var encoder = json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+// writeMessage writes the message with a Content-Length header.
+func writeMessage(writer io.Writer, msg *message) error {
+	msg.JSONRPC = "2.0"
+	var body, err = marshal(msg)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(writer, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = writer.Write(body)
+	return err
+}
+
+// Position is a location in a document as in the protocol: Line and Character
+// start at 0 and Character counts UTF-16 code units.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is the part of a document from Start to right before End.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// utf16Length returns the number of UTF-16 code units of the runes.
+func utf16Length(runes []rune) int {
+	var length = 0
+	for _, r := range runes {
+		if r >= 0x10000 && utf8.ValidRune(r) {
+			length += 2
+		} else {
+			length++
+		}
+	}
+	return length
+}
+
+// textDocumentItem is a document opened by the client.
+type textDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Text       string `json:"text"`
+}
+
+// textDocumentIdentifier names a document.
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// didOpenParams are the parameters of textDocument/didOpen.
+type didOpenParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+// didChangeParams are the parameters of textDocument/didChange. The server
+// synchronizes whole documents, so every change holds the full text.
+type didChangeParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+// didCloseParams are the parameters of textDocument/didClose.
+type didCloseParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+// textDocumentPositionParams are the parameters of textDocument/hover and
+// textDocument/completion.
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// formattingParams are the parameters of textDocument/formatting.
+type formattingParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+// initializeParams are the parameters of initialize that the server uses.
+type initializeParams struct {
+	InitializationOptions *Schema `json:"initializationOptions"`
+}
+
+// Severities of diagnostics.
+const (
+	severityError   = 1
+	severityWarning = 2
+)
+
+// diagnostic is a problem in a document.
+type diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// publishDiagnosticsParams are the parameters of
+// textDocument/publishDiagnostics.
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+// markupContent is Markdown text for the client.
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// hover is the result of textDocument/hover.
+type hover struct {
+	Contents markupContent `json:"contents"`
+	Range    Range         `json:"range"`
+}
+
+// Kinds of completion items.
+const (
+	kindVariable = 6
+	kindKeyword  = 14
+	kindConstant = 21
+)
+
+// completionItem is a suggestion of textDocument/completion.
+type completionItem struct {
+	Label  string `json:"label"`
+	Kind   int    `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// textEdit replaces the text in the Range by NewText.
+type textEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
@@ -0,0 +1,449 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/format"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/transform"
+)
+
+// Variable is a variable that rules may use.
+type Variable struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Schema lists the known variables and the keys of the rules in YAML
+// documents, e.g. read from a JSON file like
+// {"variables": [{"name": "admin", "description": "the user is an admin"}], "keys": ["when"]}.
+// If there are any variables, the server completes them and warns about other
+// variables. If there are no keys, the DefaultKeys hold rules.
+type Schema struct {
+	Variables []Variable `json:"variables"`
+	Keys      []string   `json:"keys,omitempty"`
+}
+
+// Server is a language server for Boolean expressions. In YAML documents the
+// rules are the values of the keys of the Schema, any other document holds one
+// expression. The server only finds values on the line of their key or of
+// their sequence item and literal or folded values below it. It doesn't check
+// values in flow collections like {when: a}, plain or quoted values that
+// continue on the next line and quoted values with escapes like "a\tb".
+// The server publishes syntax errors and unknown variables as diagnostics,
+// shows the simplified form of the expression under the cursor on hover,
+// completes variables, keywords and constants and formats the rules with
+// format.String.
+type Server struct {
+	schema    Schema
+	documents map[string]*document
+	writer    io.Writer
+	shutdown  bool
+}
+
+// document is an open document and the rules in it.
+type document struct {
+	text  string
+	yaml  bool
+	rules []rule
+
+	// vars are the variables of the rules that could be parsed, or of the last
+	// text in which any rule could be parsed. They are completed while the
+	// text is incomplete.
+	vars []string
+}
+
+// rule is an expression in a document and the result of parsing it.
+type rule struct {
+
+	// offset is the number of code points in the document before the text.
+	offset int
+
+	// text is the expression, for YAML the content of a scalar.
+	text string
+
+	// plain is true for an unquoted YAML scalar, which mustn't start with "!".
+	plain bool
+
+	node ast.Node
+	err  error
+}
+
+// NewServer creates a server with the known variables of the schema. Clients
+// may add variables with the same structure in the initializationOptions.
+func NewServer(schema Schema) *Server {
+	return &Server{schema: schema, documents: make(map[string]*document)}
+}
+
+// errExit is returned by handle for the exit notification.
+var errExit = errors.New("exit")
+
+// Serve reads messages from reader and writes the responses and notifications
+// to writer until the client sends the exit notification or closes the
+// reader.
+func (s *Server) Serve(reader io.Reader, writer io.Writer) error {
+	s.writer = writer
+	var buffered = bufio.NewReader(reader)
+	for {
+		var body, err = readMessage(buffered)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			err = writeMessage(writer, &message{ID: json.RawMessage("null"),
+				Error: &responseError{codeParseError, err.Error()}})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.handle(&msg); err == errExit {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a message and responds to requests.
+func (s *Server) handle(msg *message) error {
+	if msg.ID == nil {
+		return s.notify(msg.Method, msg.Params)
+	}
+	var result, failure = s.request(msg.Method, msg.Params)
+	var response = &message{ID: msg.ID, Error: failure}
+	if failure == nil {
+		var encoded, err = marshal(result)
+		if err != nil {
+			return err
+		}
+		response.Result = encoded
+	}
+	return writeMessage(s.writer, response)
+}
+
+// request handles a request and returns its result.
+func (s *Server) request(method string, params json.RawMessage) (interface{}, *responseError) {
+	if s.shutdown {
+		return nil, &responseError{codeInvalidRequest, "server is shut down"}
+	}
+	switch method {
+	case "initialize":
+		var initialize initializeParams
+		if err := unmarshalParams(params, &initialize); err != nil {
+			return nil, err
+		}
+		if initialize.InitializationOptions != nil {
+			s.schema.Variables = append(s.schema.Variables, initialize.InitializationOptions.Variables...)
+			s.schema.Keys = append(s.schema.Keys, initialize.InitializationOptions.Keys...)
+		}
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":           1,
+				"hoverProvider":              true,
+				"completionProvider":         map[string]interface{}{},
+				"documentFormattingProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "boolexpr"},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/hover":
+		var position textDocumentPositionParams
+		if err := unmarshalParams(params, &position); err != nil {
+			return nil, err
+		}
+		return s.hover(position), nil
+	case "textDocument/completion":
+		var position textDocumentPositionParams
+		if err := unmarshalParams(params, &position); err != nil {
+			return nil, err
+		}
+		return s.complete(position.TextDocument.URI), nil
+	case "textDocument/formatting":
+		var formatting formattingParams
+		if err := unmarshalParams(params, &formatting); err != nil {
+			return nil, err
+		}
+		return s.format(formatting.TextDocument.URI), nil
+	}
+	return nil, &responseError{codeMethodNotFound, fmt.Sprintf("method %q not found", method)}
+}
+
+// unmarshalParams decodes the parameters of a request.
+func unmarshalParams(params json.RawMessage, value interface{}) *responseError {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, value); err != nil {
+		return &responseError{codeInvalidParams, err.Error()}
+	}
+	return nil
+}
+
+// notify handles a notification. Unknown notifications and notifications
+// with invalid parameters are ignored as the protocol requires.
+func (s *Server) notify(method string, params json.RawMessage) error {
+	switch method {
+	case "exit":
+		return errExit
+	case "textDocument/didOpen":
+		var open didOpenParams
+		if json.Unmarshal(params, &open) == nil {
+			var uri = open.TextDocument.URI
+			var yaml = open.TextDocument.LanguageID == "yaml" || strings.HasSuffix(uri, ".yaml") || strings.HasSuffix(uri, ".yml")
+			s.documents[uri] = &document{yaml: yaml}
+			return s.update(uri, open.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var change didChangeParams
+		if json.Unmarshal(params, &change) == nil && len(change.ContentChanges) > 0 && s.documents[change.TextDocument.URI] != nil {
+			return s.update(change.TextDocument.URI, change.ContentChanges[len(change.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		var closing didCloseParams
+		if json.Unmarshal(params, &closing) == nil {
+			delete(s.documents, closing.TextDocument.URI)
+			return s.publish(closing.TextDocument.URI, []diagnostic{})
+		}
+	}
+	return nil
+}
+
+// update parses the rules in the new text of a document and publishes its
+// diagnostics.
+func (s *Server) update(uri string, text string) error {
+	var doc = s.documents[uri]
+	doc.text = text
+	if doc.yaml {
+		var keys = s.schema.Keys
+		if len(keys) == 0 {
+			keys = DefaultKeys
+		}
+		doc.rules = yamlRules(text, keys)
+	} else {
+		doc.rules = []rule{{text: text}}
+	}
+	var vars []string
+	var parsed = false
+	for index := range doc.rules {
+		var current = &doc.rules[index]
+		current.node, current.err = boolparser.ParseWithSpans(current.text)
+		if current.err == nil {
+			parsed = true
+			vars = append(vars, ast.Vars(current.node)...)
+		}
+	}
+	if parsed {
+		doc.vars = vars
+	}
+	return s.publish(uri, s.diagnose(doc))
+}
+
+// publish sends the diagnostics of a document to the client.
+func (s *Server) publish(uri string, diagnostics []diagnostic) error {
+	var params, err = marshal(publishDiagnosticsParams{uri, diagnostics})
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.writer, &message{Method: "textDocument/publishDiagnostics", Params: params})
+}
+
+// diagnose returns the syntax errors and the unknown variables of the rules
+// in a document. Empty rules are fine.
+func (s *Server) diagnose(doc *document) []diagnostic {
+	var diagnostics = []diagnostic{}
+	var known = make(map[string]bool)
+	for _, variable := range s.schema.Variables {
+		known[variable.Name] = true
+	}
+	for _, current := range doc.rules {
+		var syntaxError *boolparser.SyntaxError
+		if errors.As(current.err, &syntaxError) {
+			// The first line of the error is the location and the message.
+			var firstLine = strings.SplitN(syntaxError.Error(), "\n", 2)[0]
+			var _, message, _ = strings.Cut(firstLine, ": ")
+			var start = doc.position(current.offset + syntaxError.Position.Offset)
+			var end = doc.position(current.offset + syntaxError.Position.Offset + 1)
+			if end.Line != start.Line || syntaxError.Position.Offset >= len([]rune(current.text)) {
+				end = start
+			}
+			diagnostics = append(diagnostics, diagnostic{Range{start, end}, severityError, "boolexpr", message})
+		}
+		if current.err != nil || len(known) == 0 {
+			continue
+		}
+		ast.Inspect(current.node, func(node ast.Node) bool {
+			var spanned, ok = node.(ast.Spanned)
+			if val, isVal := ast.StripSpans(node).(ast.Val); ok && isVal && !known[val.Name] {
+				diagnostics = append(diagnostics, diagnostic{doc.span(current.offset, spanned.Location), severityWarning,
+					"boolexpr", fmt.Sprintf("unknown variable %q", val.Name)})
+			}
+			return true
+		})
+	}
+	return diagnostics
+}
+
+// hover returns the simplified form of the innermost node at the position or
+// nil if there is none.
+func (s *Server) hover(params textDocumentPositionParams) *hover {
+	var doc, found = s.documents[params.TextDocument.URI]
+	if !found {
+		return nil
+	}
+	var offset = doc.offset(params.Position)
+	for _, current := range doc.rules {
+		if current.err != nil {
+			continue
+		}
+		var spanned, ok = innermost(current.node, offset-current.offset)
+		if !ok {
+			continue
+		}
+		var node = ast.StripSpans(spanned)
+		var text = fmt.Sprintf("`%s`", format.String(node))
+		if val, isVal := node.(ast.Val); isVal {
+			for _, variable := range s.schema.Variables {
+				if variable.Name == val.Name && variable.Description != "" {
+					text += ": " + variable.Description
+				}
+			}
+		} else {
+			text += fmt.Sprintf(" simplifies to `%s`", format.String(transform.Simplify(node)))
+		}
+		return &hover{markupContent{"markdown", text}, doc.span(current.offset, spanned.Location)}
+	}
+	return nil
+}
+
+// innermost returns the deepest node whose span contains the offset or ends
+// at it, i. e. the node right before the cursor, too.
+func innermost(node ast.Node, offset int) (ast.Spanned, bool) {
+	var spanned, ok = node.(ast.Spanned)
+	if !ok || offset < spanned.Location.Start.Offset || offset > spanned.Location.End.Offset {
+		return ast.Spanned{}, false
+	}
+	for _, operand := range ast.Operands(spanned.Ex) {
+		if inner, found := innermost(operand, offset); found {
+			return inner, true
+		}
+	}
+	return spanned, true
+}
+
+// complete returns the variables of the schema and of the document, the
+// keywords and the constants.
+func (s *Server) complete(uri string) []completionItem {
+	var items []completionItem
+	var listed = make(map[string]bool)
+	for _, variable := range s.schema.Variables {
+		if !listed[variable.Name] {
+			listed[variable.Name] = true
+			items = append(items, completionItem{variable.Name, kindVariable, variable.Description})
+		}
+	}
+	if doc, found := s.documents[uri]; found {
+		for _, name := range doc.vars {
+			if !listed[name] {
+				listed[name] = true
+				items = append(items, completionItem{Label: name, Kind: kindVariable})
+			}
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	var keywords = boolparser.DefaultKeywords
+	for _, keyword := range []string{keywords.Not, keywords.And, keywords.Or} {
+		items = append(items, completionItem{Label: keyword, Kind: kindKeyword})
+	}
+	return append(items, completionItem{Label: "true", Kind: kindConstant},
+		completionItem{Label: "false", Kind: kindConstant})
+}
+
+// format returns edits that replace the rules of a document by their
+// formatted expressions. The spaces around a rule are kept. Rules that can't
+// be parsed or are formatted already aren't changed.
+func (s *Server) format(uri string) []textEdit {
+	var doc, found = s.documents[uri]
+	if !found {
+		return nil
+	}
+	var edits = []textEdit{}
+	for _, current := range doc.rules {
+		if current.err != nil {
+			continue
+		}
+		var text = format.String(ast.StripSpans(current.node))
+		if current.plain && strings.HasPrefix(text, "!") {
+			// A plain YAML scalar starting with "!" would be a tag.
+			text = "'" + text + "'"
+		}
+		var trimmed = strings.TrimLeft(current.text, " \t\r\n")
+		var start = current.offset + len([]rune(current.text)) - len([]rune(trimmed))
+		trimmed = strings.TrimRight(trimmed, " \t\r\n")
+		if text != trimmed {
+			var end = start + len([]rune(trimmed))
+			edits = append(edits, textEdit{Range{doc.position(start), doc.position(end)}, text})
+		}
+	}
+	return edits
+}
+
+// position converts an offset in code points to a Position.
+func (doc *document) position(offset int) Position {
+	var result Position
+	var line []rune
+	for index, r := range []rune(doc.text) {
+		if index >= offset {
+			break
+		}
+		if r == '\n' {
+			result.Line++
+			line = line[:0]
+		} else {
+			line = append(line, r)
+		}
+	}
+	result.Character = utf16Length(line)
+	return result
+}
+
+// offset converts a Position to an offset in code points. Positions after the
+// end of a line are moved to its end.
+func (doc *document) offset(position Position) int {
+	var lines = strings.Split(doc.text, "\n")
+	var offset = 0
+	for index := 0; index < position.Line && index < len(lines); index++ {
+		offset += len([]rune(lines[index])) + 1
+	}
+	if position.Line >= len(lines) {
+		return offset
+	}
+	var units = 0
+	for _, r := range lines[position.Line] {
+		units += utf16Length([]rune{r})
+		if units > position.Character {
+			break
+		}
+		offset++
+	}
+	return offset
+}
+
+// span converts the span of a node in a rule that starts at the offset to a
+// Range.
+func (doc *document) span(offset int, span ast.Span) Range {
+	return Range{doc.position(offset + span.Start.Offset), doc.position(offset + span.End.Offset)}
+}
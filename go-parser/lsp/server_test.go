@@ -0,0 +1,149 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testServe sends the messages to a server and returns the messages that it
+// writes in the compact JSON encoding.
+func testServe(t *testing.T, schema Schema, messages ...string) []string {
+	var input bytes.Buffer
+	for _, msg := range messages {
+		fmt.Fprintf(&input, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+	var output bytes.Buffer
+	if err := NewServer(schema).Serve(&input, &output); err != nil {
+		t.Fatalf("Serve failed! Expected no error but got wrong result %v !", err)
+	}
+	var result []string
+	var reader = bufio.NewReader(&output)
+	for reader.Buffered() > 0 || output.Len() > 0 {
+		var body, err = readMessage(reader)
+		if err != nil {
+			t.Fatalf("Reading the output failed! Expected a message but got wrong result %v !", err)
+		}
+		result = append(result, string(body))
+	}
+	return result
+}
+
+// open returns a didOpen notification for the document file:///rule.
+func open(text string) string {
+	var encoded, _ = json.Marshal(text)
+	return `{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///rule","text":` +
+		string(encoded) + `}}}`
+}
+
+// change returns a didChange notification for the document file:///rule.
+func change(text string) string {
+	var encoded, _ = json.Marshal(text)
+	return `{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///rule"},` +
+		`"contentChanges":[{"text":` + string(encoded) + `}]}}`
+}
+
+// request returns a request for the document file:///rule at the position.
+func request(id int, method string, line int, character int) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q,"params":{"textDocument":{"uri":"file:///rule"},`+
+		`"position":{"line":%d,"character":%d}}}`, id, method, line, character)
+}
+
+func testMessages(t *testing.T, name string, result []string, expected []string) {
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("%v failed! Expected\n%v\nbut got wrong result\n%v", name, strings.Join(expected, "\n"), strings.Join(result, "\n"))
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	var result = testServe(t, Schema{},
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"unknown"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"textDocument/hover"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"shutdown"}`)
+	testMessages(t, "Lifecycle", result, []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"capabilities":{"completionProvider":{},"documentFormattingProvider":true,` +
+			`"hoverProvider":true,"textDocumentSync":1},"serverInfo":{"name":"boolexpr"}}}`,
+		`{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"method \"unknown\" not found"}}`,
+		`{"jsonrpc":"2.0","id":3,"result":null}`,
+		`{"jsonrpc":"2.0","id":4,"error":{"code":-32600,"message":"server is shut down"}}`,
+	})
+}
+
+func TestDiagnostics(t *testing.T) {
+	var schema = Schema{Variables: []Variable{{Name: "admin"}}}
+	var result = testServe(t, schema, open("admin &\n  (b | "), open("admin | b\n"),
+		`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///rule"},`+
+			`"contentChanges":[{"text":"admin ) b"}]}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didClose","params":{"textDocument":{"uri":"file:///rule"}}}`,
+		open(" "))
+	var prefix = `{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"uri":"file:///rule","diagnostics":`
+	testMessages(t, "Diagnostics", result, []string{
+		prefix + `[{"range":{"start":{"line":1,"character":7},"end":{"line":1,"character":7}},"severity":1,` +
+			`"source":"boolexpr","message":"unexpected end of input, expected \"!\", \"not\", \"true\", \"false\", identifier or \"(\""}]}}`,
+		prefix + `[{"range":{"start":{"line":0,"character":8},"end":{"line":0,"character":9}},"severity":2,` +
+			`"source":"boolexpr","message":"unknown variable \"b\""}]}}`,
+		prefix + `[{"range":{"start":{"line":0,"character":6},"end":{"line":0,"character":7}},"severity":1,` +
+			`"source":"boolexpr","message":"unexpected ')', expected \"&\", \"and\", \"^\", \"|\", \"or\", \"->\", \"<->\" or end of input"}]}}`,
+		prefix + `[]}}`,
+		prefix + `[]}}`,
+	})
+}
+
+func TestHover(t *testing.T) {
+	var schema = Schema{Variables: []Variable{{Name: "admin", Description: "the user is an admin"}}}
+	var result = testServe(t, schema, open("admin & (b | !b)"),
+		request(1, "textDocument/hover", 0, 2), request(2, "textDocument/hover", 0, 8),
+		request(3, "textDocument/hover", 0, 6), request(4, "textDocument/hover", 1, 0))
+	testMessages(t, "Hover", result[1:], []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"contents":{"kind":"markdown","value":"` + "`admin`" + `: the user is an admin"},` +
+			`"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":5}}}}`,
+		`{"jsonrpc":"2.0","id":2,"result":{"contents":{"kind":"markdown","value":"` + "`b | !b` simplifies to `true`" + `"},` +
+			`"range":{"start":{"line":0,"character":8},"end":{"line":0,"character":16}}}}`,
+		`{"jsonrpc":"2.0","id":3,"result":{"contents":{"kind":"markdown","value":"` + "`admin & (b | !b)` simplifies to `admin`" + `"},` +
+			`"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":16}}}}`,
+		`{"jsonrpc":"2.0","id":4,"result":null}`,
+	})
+}
+
+func TestCompletion(t *testing.T) {
+	var schema = Schema{Variables: []Variable{{Name: "user", Description: "a user is logged in"}}}
+	var result = testServe(t, schema,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"initializationOptions":{"variables":[{"name":"admin"}]}}}`,
+		open("guest | user"), change("guest | "), request(2, "textDocument/completion", 0, 8))
+	testMessages(t, "Completion", result[3:], []string{
+		`{"jsonrpc":"2.0","id":2,"result":[{"label":"admin","kind":6},{"label":"guest","kind":6},` +
+			`{"label":"user","kind":6,"detail":"a user is logged in"},{"label":"not","kind":14},{"label":"and","kind":14},` +
+			`{"label":"or","kind":14},{"label":"true","kind":21},{"label":"false","kind":21}]}`,
+	})
+}
+
+func TestFormatting(t *testing.T) {
+	var formatting = `{"jsonrpc":"2.0","id":1,"method":"textDocument/formatting","params":{"textDocument":{"uri":"file:///rule"}}}`
+	var result = testServe(t, Schema{}, open("((a) & b) |\n  c\n"), formatting, change("a & b"), formatting, change("a &"), formatting)
+	testMessages(t, "Formatting", []string{result[1], result[3], result[5]}, []string{
+		`{"jsonrpc":"2.0","id":1,"result":[{"range":{"start":{"line":0,"character":0},"end":{"line":1,"character":3}},"newText":"a & b | c"}]}`,
+		`{"jsonrpc":"2.0","id":1,"result":[]}`,
+		`{"jsonrpc":"2.0","id":1,"result":[]}`,
+	})
+}
+
+func TestPositions(t *testing.T) {
+	var doc = &document{text: "a &\n😀 b\nc"}
+	for offset, expected := range map[int]Position{0: {0, 0}, 3: {0, 3}, 4: {1, 0}, 5: {1, 2}, 7: {1, 4}, 8: {2, 0}, 9: {2, 1}} {
+		var result = doc.position(offset)
+		if result != expected {
+			t.Errorf("position on input \"%v\" failed! Expected %v but got wrong result %v !", offset, expected, result)
+		}
+		if back := doc.offset(result); back != offset {
+			t.Errorf("offset on input \"%v\" failed! Expected %v but got wrong result %v !", result, offset, back)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package lsp
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultKeys are the keys of the values that hold rules in YAML documents if
+// the Schema doesn't list any.
+var DefaultKeys = []string{"rule", "rules", "when", "condition"}
+
+// yamlKey matches the key of a mapping entry at the beginning of a line, e.g.
+// "when:" or "'when' :".
+var yamlKey = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s#'"\-?:,\[\]{}][^#]*?)\s*:(\s|$)`)
+
+// yamlRules finds the rules in a YAML document: the scalars that are the
+// values of the keys or the items of sequences that are the values of the
+// keys. It understands the block style of YAML, i. e. plain, quoted, literal
+// and folded scalars that are written in block mappings and sequences. Flow
+// collections, plain and quoted scalars that continue on the next line and
+// quoted scalars with escapes aren't rules.
+// The text of a literal or folded scalar is taken as it is including the
+// indentation, which the parser skips like all spaces.
+func yamlRules(text string, keys []string) []rule {
+	var isKey = make(map[string]bool)
+	for _, key := range keys {
+		isKey[key] = true
+	}
+	var lines = strings.Split(text, "\n")
+	var starts = make([]int, len(lines))
+	var offset = 0
+	for index, line := range lines {
+		starts[index] = offset
+		offset += utf8.RuneCountInString(line) + 1
+	}
+	var scanner = yamlScanner{lines, starts, nil}
+	// owner is the indentation of a key of rules whose value is a block
+	// sequence or -1 if the current lines don't belong to one.
+	var owner = -1
+	for index := 0; index < len(lines); index++ {
+		var line = strings.TrimSuffix(lines[index], "\r")
+		var content = strings.TrimLeft(line, " ")
+		var indent = len(line) - len(content)
+		if content == "" || content[0] == '#' || content == "---" || content == "..." {
+			continue
+		}
+		var column, item = indent, false
+		for content == "-" || strings.HasPrefix(content, "- ") {
+			item = true
+			var rest = strings.TrimLeft(content[1:], " ")
+			column += len(content) - len(rest)
+			content = rest
+		}
+		if indent < owner || indent == owner && !item {
+			owner = -1
+		}
+		var match = yamlKey.FindStringSubmatchIndex(content)
+		if match == nil {
+			if item && owner >= 0 && content != "" {
+				index = scanner.value(index, column, indent)
+			}
+			continue
+		}
+		var key = strings.Trim(content[match[2]:match[3]], `"'`)
+		var value = column + match[1]
+		for value < len(line) && line[value] == ' ' {
+			value++
+		}
+		if !isKey[key] {
+			continue
+		}
+		if value >= len(line) || line[value] == '#' {
+			owner = column
+			continue
+		}
+		index = scanner.value(index, value, column)
+	}
+	return scanner.rules
+}
+
+// yamlScanner collects the rules of a YAML document.
+type yamlScanner struct {
+	lines []string
+
+	// starts are the offsets of the lines in code points.
+	starts []int
+
+	rules []rule
+}
+
+// value adds the scalar that starts at the byte column of the line with the
+// index as a rule. The scalar belongs to a key or a sequence item with the
+// indentation parent. value returns the index of the last line of the scalar.
+func (scanner *yamlScanner) value(index int, column int, parent int) int {
+	var line = strings.TrimSuffix(scanner.lines[index], "\r")
+	var value = line[column:]
+	var start, end, plain = column + 1, -1, false
+	switch value[0] {
+	case '|', '>':
+		return scanner.block(index, parent)
+	case '"', '\'':
+		// Quoted scalars with escapes are skipped since the text of the rule
+		// has to be the text in the document. Rules don't need escapes.
+		var closing = strings.IndexByte(value[1:], value[0]) + 1
+		if closing == 0 {
+			break
+		}
+		var escaped = value[0] == '"' && strings.Contains(value[1:closing], `\`) ||
+			value[0] == '\'' && strings.HasPrefix(value[closing+1:], "'")
+		if !escaped {
+			end = column + closing
+		}
+	case '[', '{', '&', '*', '!', '%', '@', '`':
+		// Flow collections, anchors, aliases, tags and reserved indicators.
+	default:
+		start, end, plain = column, len(line), true
+		if comment := strings.Index(value, " #"); comment >= 0 {
+			end = column + comment
+		}
+		end = start + len(strings.TrimRight(line[start:end], " \t"))
+	}
+	if end > start {
+		scanner.rules = append(scanner.rules, rule{
+			offset: scanner.starts[index] + utf8.RuneCountInString(line[:start]),
+			text:   line[start:end],
+			plain:  plain,
+		})
+	}
+	return index
+}
+
+// block adds the literal or folded scalar that starts after the line with
+// the index. Its lines are blank or indented deeper than parent.
+func (scanner *yamlScanner) block(index int, parent int) int {
+	var last = index
+	for next := index + 1; next < len(scanner.lines); next++ {
+		var line = strings.TrimSuffix(scanner.lines[next], "\r")
+		var content = strings.TrimLeft(line, " ")
+		if content == "" {
+			continue
+		}
+		if len(line)-len(content) <= parent {
+			break
+		}
+		last = next
+	}
+	if last > index {
+		var text strings.Builder
+		for line := index + 1; line <= last; line++ {
+			if line > index+1 {
+				text.WriteString("\n")
+			}
+			text.WriteString(scanner.lines[line])
+		}
+		scanner.rules = append(scanner.rules, rule{offset: scanner.starts[index+1], text: text.String()})
+	}
+	return last
+}
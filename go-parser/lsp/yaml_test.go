@@ -0,0 +1,88 @@
+package lsp
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestYAMLRules(t *testing.T) {
+	var text = "# access rules\n" +
+		"rules:\n" +
+		"  - name: admins\n" +
+		"    when: admin & !guest  # comment\n" +
+		"  - when: \"!guest\"\n" +
+		"    condition: |\n" +
+		"      a &\n" +
+		"        b\n" +
+		"    other: c\n" +
+		"  - 'a ^ b'\n" +
+		"checks:\n" +
+		"  when: [a, b]\n" +
+		"  rule: !tag a\n" +
+		"rule:\n" +
+		"- x\n" +
+		"- y -> z\n" +
+		"name: w\n"
+	var expected = []rule{
+		{offset: 49, text: "admin & !guest", plain: true},
+		{offset: 86, text: "!guest"},
+		{offset: 111, text: "      a &\n        b"},
+		{offset: 149, text: "a ^ b"},
+		{offset: 202, text: "x", plain: true},
+		{offset: 206, text: "y -> z", plain: true},
+	}
+	if result := yamlRules(text, DefaultKeys); !reflect.DeepEqual(result, expected) {
+		t.Errorf("yamlRules on input %q failed! Expected %v but got wrong result %v !", text, expected, result)
+	}
+	if result := yamlRules(text, []string{"other"}); !reflect.DeepEqual(result, []rule{{offset: 142, text: "c", plain: true}}) {
+		t.Errorf("yamlRules with the key \"other\" failed! Expected the rule \"c\" but got wrong result %v !", result)
+	}
+}
+
+func TestYAMLQuoted(t *testing.T) {
+	var text = "when: \"a\\tb\"\n" +
+		"when: \"a \\\" b\"\n" +
+		"when: 'a '' b'\n" +
+		"when: \"a &\n" +
+		"  b\"\n" +
+		"when: \"a & b\"\n" +
+		"when: 'a | b'  # c\n"
+	var expected = []rule{{offset: 66, text: "a & b"}, {offset: 80, text: "a | b"}}
+	if result := yamlRules(text, DefaultKeys); !reflect.DeepEqual(result, expected) {
+		t.Errorf("yamlRules on input %q failed! Expected %v but got wrong result %v !", text, expected, result)
+	}
+}
+
+func TestYAMLDocument(t *testing.T) {
+	var schema = Schema{Variables: []Variable{{Name: "admin"}, {Name: "guest"}}}
+	var text = "rules:\n" +
+		"  - when: admin &\n" +
+		"  - when: \"(admin) | !guest\"\n" +
+		"    unless: nobody\n" +
+		"  - when: |\n" +
+		"      !!guest & staff\n"
+	var open = `{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///rules.yaml",` +
+		`"languageId":"yaml","text":` + quote(text) + `}}}`
+	var hover = `{"jsonrpc":"2.0","id":1,"method":"textDocument/hover","params":{"textDocument":{"uri":"file:///rules.yaml"},` +
+		`"position":{"line":5,"character":6}}}`
+	var formatting = `{"jsonrpc":"2.0","id":2,"method":"textDocument/formatting","params":{"textDocument":{"uri":"file:///rules.yaml"}}}`
+	var result = testServe(t, schema, open, hover, formatting)
+	testMessages(t, "YAML document", result, []string{
+		`{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"uri":"file:///rules.yaml","diagnostics":[` +
+			`{"range":{"start":{"line":1,"character":17},"end":{"line":1,"character":17}},"severity":1,"source":"boolexpr",` +
+			`"message":"unexpected end of input, expected \"!\", \"not\", \"true\", \"false\", identifier or \"(\""},` +
+			`{"range":{"start":{"line":5,"character":16},"end":{"line":5,"character":21}},"severity":2,"source":"boolexpr",` +
+			`"message":"unknown variable \"staff\""}]}}`,
+		`{"jsonrpc":"2.0","id":1,"result":{"contents":{"kind":"markdown","value":"` + "`!!guest` simplifies to `guest`" + `"},` +
+			`"range":{"start":{"line":5,"character":6},"end":{"line":5,"character":13}}}}`,
+		`{"jsonrpc":"2.0","id":2,"result":[` +
+			`{"range":{"start":{"line":2,"character":11},"end":{"line":2,"character":27}},"newText":"admin | !guest"}]}`,
+	})
+}
+
+// quote returns the text as a JSON string.
+func quote(text string) string {
+	var encoded, _ = json.Marshal(text)
+	return string(encoded)
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Failure describes why a parser didn't accept its Input. The combinators
+// merge the failures of their parsers so that the failure that got furthest
+// into the Input wins. That's usually the one that makes sense to humans.
+type Failure struct {
+
+	// Offset is the number of code points that precede the location of the
+	// failure.
+	Offset int
+
+	// EndOfInput is true if the parser ran out of Input.
+	EndOfInput bool
+
+	// Expected describes everything that would have been accepted at the
+	// location of the failure, e.g. "\"|\"" or "identifier".
+	Expected []string
+}
+
+// Expectation describes the expected alternatives in plain English, e.g.
+// `expected "(", "!" or identifier`. It's empty if nothing was expected.
+func (failure *Failure) Expectation() string {
+	var count = len(failure.Expected)
+	if count == 0 {
+		return ""
+	}
+	if count == 1 {
+		return "expected " + failure.Expected[0]
+	}
+	return "expected " + strings.Join(failure.Expected[:count-1], ", ") +
+		" or " + failure.Expected[count-1]
+}
+
+// Error implements the error interface.
+func (failure *Failure) Error() string {
+	var message = fmt.Sprintf("unexpected input at offset %d", failure.Offset)
+	if failure.EndOfInput {
+		message = fmt.Sprintf("unexpected end of input at offset %d", failure.Offset)
+	}
+	if len(failure.Expected) > 0 {
+		message += ", " + failure.Expectation()
+	}
+	return message
+}
+
+// isBefore reports whether the failure happened before the other one.
+func (failure *Failure) isBefore(other *Failure) bool {
+	return failure.Offset < other.Offset
+}
+
+// failureAt creates a Failure at the beginning of the Input.
+func failureAt(Input Input, expected ...string) *Failure {
+	return &Failure{Input.Offset(), Input.AtEnd(), expected}
+}
+
+// furthestFailure returns the failure that got further into the Input. If both
+// got equally far then it merges their expectations. Either one may be nil.
+func furthestFailure(first *Failure, second *Failure) *Failure {
+	if first == nil {
+		return second
+	}
+	if second == nil || second.isBefore(first) {
+		return first
+	}
+	if first.isBefore(second) {
+		return second
+	}
+	var merged = &Failure{first.Offset, first.EndOfInput,
+		append([]string(nil), first.Expected...)}
+	for _, expected := range second.Expected {
+		if !contains(merged.Expected, expected) {
+			merged.Expected = append(merged.Expected, expected)
+		}
+	}
+	return merged
+}
+
+func contains(texts []string, text string) bool {
+	for _, candidate := range texts {
+		if candidate == text {
+			return true
+		}
+	}
+	return false
+}
+
+// quote describes code points the way they're expected in a Failure.
+func quote(codePoints ...rune) string {
+	return strconv.Quote(string(codePoints))
+}
+
+// Named gives the parser a name for error messages. If the parser fails at the
+// beginning of the Input then the Failure expects this name instead of the
+// details the parser itself came up with. An empty name hides the parser from
+// error messages altogether, which is useful for things like optional spaces.
+func (parser Parser[T]) Named(name string) Parser[T] {
+	return func(Input Input) Result[T] {
+		var result = parser(Input)
+		if result.Failure != nil && !failureAt(Input).isBefore(result.Failure) {
+			var renamed = *result.Failure
+			renamed.Expected = nil
+			if name != "" {
+				renamed.Expected = []string{name}
+			}
+			result.Failure = &renamed
+		}
+		return result
+	}
+}
+
+// Position is a location in a text that's meant for humans.
+type Position struct {
+
+	// Offset is the number of code points that precede the location.
+	Offset int
+
+	// Line is the line of the location. The first line is 1.
+	Line int
+
+	// Column is the code point in the Line. The first column is 1.
+	Column int
+}
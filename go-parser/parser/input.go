@@ -0,0 +1,306 @@
+package parser
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+// Input is anything that can produce a sequence of code points.
+// RuneArrayInput, ByteSliceInput and ReaderInput are the implementations that
+// you can use. See StringToInput, BytesToInput and ReaderToInput if you want to
+// create Input directly from a string, a byte slice or an io.Reader.
+//
+// The end of the Input is an Input, too: AtEnd returns true for it and its
+// CurrentCodePoint is EndOfInput.
+type Input interface {
+
+	// CurrentCodePoint returns the rune at the beginning of this Input or
+	// EndOfInput if there's nothing left.
+	CurrentCodePoint() rune
+
+	// RemainingInput returns everything that comes after the current code point.
+	// At the end of the Input it returns the end of the Input again.
+	RemainingInput() Input
+
+	// Offset returns the number of code points that precede the current code
+	// point in the whole text.
+	Offset() int
+
+	// AtEnd returns true if there are no code points left.
+	AtEnd() bool
+}
+
+// EndOfInput is the CurrentCodePoint of an Input that is AtEnd. It's not a
+// valid code point, so it never matches anything by accident.
+const EndOfInput rune = -1
+
+// RuneArrayInput is an implementation of Input.
+// You can use StringToInput to create instances of this type directly
+// from strings.
+type RuneArrayInput struct {
+
+	// Text is the whole Input text. Please keep it unchanged while parsers are
+	// working on it.
+	Text []rune
+
+	// CurrentPosition points to the current code point in the Text
+	CurrentPosition int
+
+	// memo caches the results of memoized parsers, see Memoize. It's nil
+	// unless the RuneArrayInput was created by StringToInput.
+	memo *memoTable
+}
+
+// RemainingInput is necessary for RuneArrayInput to implement Input
+func (Input RuneArrayInput) RemainingInput() Input {
+	if Input.AtEnd() {
+		return Input
+	}
+	return RuneArrayInput{Input.Text, Input.CurrentPosition + 1, Input.memo}
+}
+
+// Offset is necessary for RuneArrayInput to implement Input.
+func (Input RuneArrayInput) Offset() int {
+	return Input.CurrentPosition
+}
+
+// AtEnd is necessary for RuneArrayInput to implement Input.
+func (Input RuneArrayInput) AtEnd() bool {
+	return Input.CurrentPosition >= len(Input.Text)
+}
+
+// Position derives the line and the column of the current code point from
+// CurrentPosition. It scans the Text from the start on every call, so it
+// takes time proportional to the offset. Call it to report a result, e.g. a
+// failure, but not in a hot path.
+func (Input RuneArrayInput) Position() Position {
+	var position = Position{0, 1, 1}
+	for _, codePoint := range Input.Text {
+		if position.Offset >= Input.CurrentPosition {
+			break
+		}
+		position = position.advance(codePoint)
+	}
+	return position
+}
+
+// memoTable is necessary for RuneArrayInput to support Memoize.
+func (Input RuneArrayInput) memoTable() *memoTable {
+	return Input.memo
+}
+
+// CurrentCodePoint is necessary for RuneArrayInput to implement Input.
+func (Input RuneArrayInput) CurrentCodePoint() rune {
+	if Input.AtEnd() {
+		return EndOfInput
+	}
+	return Input.Text[Input.CurrentPosition]
+}
+
+// StringToInput converts a string to a RuneArrayInput so you can use parsers on it.
+// The RuneArrayInput supports memoized parsers, see Memoize.
+func StringToInput(Text string) Input {
+	return RuneArrayInput{[]rune(Text), 0, &memoTable{}}
+}
+
+// ByteSliceInput is an implementation of Input that decodes the code points
+// from UTF-8 on the fly. Unlike RuneArrayInput it doesn't copy the text.
+// Invalid UTF-8 is decoded to utf8.RuneError one byte at a time.
+// You can use BytesToInput to create instances of this type.
+type ByteSliceInput struct {
+
+	// Text is the whole Input text in UTF-8. Please keep it unchanged while
+	// parsers are working on it.
+	Text []byte
+
+	// CurrentPosition is the index of the first byte of the current code point
+	// in the Text.
+	CurrentPosition int
+
+	// offset is the number of code points that precede CurrentPosition.
+	offset int
+
+	// memo caches the results of memoized parsers, see Memoize. It's nil
+	// unless the ByteSliceInput was created by BytesToInput.
+	memo *memoTable
+}
+
+// CurrentCodePoint is necessary for ByteSliceInput to implement Input.
+func (Input ByteSliceInput) CurrentCodePoint() rune {
+	if Input.AtEnd() {
+		return EndOfInput
+	}
+	var codePoint, _ = utf8.DecodeRune(Input.Text[Input.CurrentPosition:])
+	return codePoint
+}
+
+// RemainingInput is necessary for ByteSliceInput to implement Input.
+func (Input ByteSliceInput) RemainingInput() Input {
+	if Input.AtEnd() {
+		return Input
+	}
+	var _, size = utf8.DecodeRune(Input.Text[Input.CurrentPosition:])
+	return ByteSliceInput{Input.Text, Input.CurrentPosition + size,
+		Input.offset + 1, Input.memo}
+}
+
+// Offset is necessary for ByteSliceInput to implement Input.
+func (Input ByteSliceInput) Offset() int {
+	return Input.offset
+}
+
+// AtEnd is necessary for ByteSliceInput to implement Input.
+func (Input ByteSliceInput) AtEnd() bool {
+	return Input.CurrentPosition >= len(Input.Text)
+}
+
+// Position derives the line and the column of the current code point from
+// CurrentPosition. It scans the Text from the start on every call, so it
+// takes time proportional to the offset. Call it to report a result, e.g. a
+// failure, but not in a hot path.
+func (Input ByteSliceInput) Position() Position {
+	var position = Position{0, 1, 1}
+	var text = Input.Text[:Input.CurrentPosition]
+	for len(text) > 0 {
+		var codePoint, size = utf8.DecodeRune(text)
+		position = position.advance(codePoint)
+		text = text[size:]
+	}
+	return position
+}
+
+// memoTable is necessary for ByteSliceInput to support Memoize.
+func (Input ByteSliceInput) memoTable() *memoTable {
+	return Input.memo
+}
+
+// BytesToInput creates a ByteSliceInput for UTF-8 text so you can use parsers
+// on it without copying it. The ByteSliceInput supports memoized parsers, see
+// Memoize.
+func BytesToInput(Text []byte) Input {
+	return ByteSliceInput{Text, 0, 0, &memoTable{}}
+}
+
+// readerChunkSize is the number of code points that a ReaderInput reads at once.
+const readerChunkSize = 4096
+
+// ReaderInput is an implementation of Input that reads the code points from
+// an io.Reader as the parsers need them. It reads the text in chunks, which
+// are linked forward: an Input keeps the chunk of its code point and every
+// chunk read after it in memory. Only the chunks before the first Input that's
+// still referred to are freed. Parsers that may backtrack hold on to the Input
+// they started at until they're done, so parsing a whole text with them keeps
+// all of it in memory as code points. A ReaderInput doesn't support memoized
+// parsers, because the cache would keep the whole text in memory, too.
+// You can use ReaderToInput to create instances of this type.
+type ReaderInput struct {
+
+	// chunk holds the current code point.
+	chunk *readerChunk
+
+	// index points to the current code point in the chunk.
+	index int
+
+	// position is the location of the current code point.
+	position Position
+}
+
+// readerChunk is a piece of the text read by a ReaderInput.
+type readerChunk struct {
+
+	// codePoints are the code points of the chunk. Only the last chunk, which
+	// marks the end of the Input, is empty.
+	codePoints []rune
+
+	// next is the chunk after this one. It's nil until it has been read.
+	next *readerChunk
+
+	// source reads the next chunk.
+	source *readerSource
+}
+
+// readerSource is the io.Reader behind a ReaderInput.
+type readerSource struct {
+	reader *bufio.Reader
+
+	// err is the first error that the reader returned other than io.EOF.
+	err error
+}
+
+// read reads the next chunk from the source.
+func (source *readerSource) read() *readerChunk {
+	var chunk = &readerChunk{source: source}
+	for source.err == nil && len(chunk.codePoints) < readerChunkSize {
+		var codePoint, _, err = source.reader.ReadRune()
+		if err != nil {
+			if err != io.EOF {
+				source.err = err
+			}
+			break
+		}
+		chunk.codePoints = append(chunk.codePoints, codePoint)
+	}
+	return chunk
+}
+
+// CurrentCodePoint is necessary for ReaderInput to implement Input.
+func (Input ReaderInput) CurrentCodePoint() rune {
+	if Input.AtEnd() {
+		return EndOfInput
+	}
+	return Input.chunk.codePoints[Input.index]
+}
+
+// RemainingInput is necessary for ReaderInput to implement Input.
+func (Input ReaderInput) RemainingInput() Input {
+	if Input.AtEnd() {
+		return Input
+	}
+	var position = Input.position.advance(Input.CurrentCodePoint())
+	if Input.index+1 < len(Input.chunk.codePoints) {
+		return ReaderInput{Input.chunk, Input.index + 1, position}
+	}
+	if Input.chunk.next == nil {
+		Input.chunk.next = Input.chunk.source.read()
+	}
+	return ReaderInput{Input.chunk.next, 0, position}
+}
+
+// Offset is necessary for ReaderInput to implement Input.
+func (Input ReaderInput) Offset() int {
+	return Input.position.Offset
+}
+
+// AtEnd is necessary for ReaderInput to implement Input. A ReaderInput ends
+// at the end of the text or at the first read error, see Err.
+func (Input ReaderInput) AtEnd() bool {
+	return Input.index >= len(Input.chunk.codePoints)
+}
+
+// Position returns the line and the column of the current code point.
+func (Input ReaderInput) Position() Position {
+	return Input.position
+}
+
+// Err returns the error that made the io.Reader stop before the end of the
+// text or nil if there wasn't any. Check it after parsing: a ReaderInput
+// treats read errors like the end of the text.
+func (Input ReaderInput) Err() error {
+	return Input.chunk.source.err
+}
+
+// ReaderToInput creates a ReaderInput that reads UTF-8 text from the reader
+// so you can use parsers on it.
+func ReaderToInput(reader io.Reader) Input {
+	var source = &readerSource{reader: bufio.NewReader(reader)}
+	return ReaderInput{source.read(), 0, Position{0, 1, 1}}
+}
+
+// advance returns the Position after the codePoint at this Position.
+func (position Position) advance(codePoint rune) Position {
+	if codePoint == '\n' {
+		return Position{position.Offset + 1, position.Line + 1, 1}
+	}
+	return Position{position.Offset + 1, position.Line, position.Column + 1}
+}
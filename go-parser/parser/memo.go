@@ -0,0 +1,157 @@
+package parser
+
+import "sync/atomic"
+
+// memoTable caches the results of memoized parsers for one text. All the
+// Inputs that StringToInput derives from the same text share one memoTable.
+type memoTable struct {
+
+	// results holds the cached results by parser and position.
+	results map[memoKey]*memoEntry
+
+	// seeds holds the left-recursive parsers that are currently growing their
+	// seed by the offset where they started.
+	seeds map[int]*growingSeed
+}
+
+// memoEntry is the cached result of one memoized parser at one position.
+type memoEntry struct {
+
+	// result is the Result of the parser. While the parser is still in
+	// progress it's a failure, which is what a left-recursive call gets.
+	result interface{}
+
+	// inProgress is true while the parser is parsing at this position.
+	inProgress bool
+
+	// leftRecursive is true if the parser called itself at this position.
+	leftRecursive bool
+}
+
+// growingSeed keeps track of a left-recursive parser that grows its seed.
+type growingSeed struct {
+
+	// head is the identity of the left-recursive parser.
+	head uint64
+
+	// evaluated holds the identities of the other parsers that were evaluated
+	// again at the position of the seed during the current round.
+	evaluated map[uint64]bool
+
+	// saved holds the entries from before the current round, nil if there
+	// wasn't any. They're restored if the round doesn't grow the seed.
+	saved map[memoKey]*memoEntry
+}
+
+// memoKey identifies the result of one memoized parser at one position.
+type memoKey struct {
+	parser uint64
+	offset int
+}
+
+// memoizingInput is implemented by Inputs that can hold a memoTable.
+type memoizingInput interface {
+	memoTable() *memoTable
+}
+
+// lastMemoizedParser counts the memoized parsers to give each one an identity.
+var lastMemoizedParser uint64
+
+// Memoize makes the parser remember its Result for every position of the
+// Input, so that it parses each position at most once no matter how often the
+// alternatives of a grammar try it. This is known as packrat parsing: if all
+// the recursive rules of a grammar are memoized then parsing takes linear time
+// at the cost of memory for the cached results.
+//
+// Memoized parsers may be left-recursive, i. e. rules like
+// Sum := Sum "-" Number | Number may be written down just like in a textbook
+// as long as at least one parser of every left-recursive cycle is memoized.
+// When a memoized parser calls itself at the same position, the inner call
+// fails. The outer call thus parses the non-recursive alternative, the seed.
+// Then it grows the seed: it parses again and again while the inner call
+// returns the previous result until the result doesn't get any longer.
+// This turns Sum into a left-associative chain.
+//
+// The Results are cached in the Input, so memoization only takes place if the
+// Input supports it. The Inputs created by StringToInput and BytesToInput do.
+// Create the memoized parser once and reuse it: every call to Memoize creates
+// a parser with a new identity and thus a new cache.
+func Memoize[T any](parser Parser[T]) Parser[T] {
+	var identity = atomic.AddUint64(&lastMemoizedParser, 1)
+	return func(Input Input) Result[T] {
+		var table = memoTableOf(Input)
+		if table == nil {
+			return parser(Input)
+		}
+		var key = memoKey{identity, Input.Offset()}
+		var entry, found = table.results[key]
+		var seed = table.seeds[key.offset]
+		if seed != nil && seed.head != identity && !seed.evaluated[identity] {
+			// The parser may be involved in the left recursion of the seed, so
+			// it has to see the seed of the current round.
+			seed.evaluated[identity] = true
+			if _, isSaved := seed.saved[key]; !isSaved {
+				seed.saved[key] = entry
+			}
+			found = false
+		}
+		if found {
+			if entry.inProgress {
+				entry.leftRecursive = true
+			}
+			return entry.result.(Result[T])
+		}
+		entry = &memoEntry{result: fail[T](Input, failureAt(Input)), inProgress: true}
+		table.results[key] = entry
+		var result = parser(Input)
+		entry.inProgress = false
+		entry.result = result
+		if entry.leftRecursive && result.Success && seed == nil {
+			return growSeed(table, key, entry, parser, Input)
+		}
+		return result
+	}
+}
+
+// growSeed parses again and again with the previous result in the entry as
+// long as the result gets longer. See Memoize for details.
+func growSeed[T any](table *memoTable, key memoKey, entry *memoEntry,
+	parser Parser[T], Input Input) Result[T] {
+	var seed = &growingSeed{head: key.parser}
+	table.seeds[key.offset] = seed
+	defer delete(table.seeds, key.offset)
+	var result = entry.result.(Result[T])
+	for {
+		seed.evaluated = make(map[uint64]bool)
+		seed.saved = make(map[memoKey]*memoEntry)
+		var next = parser(Input)
+		if !next.Success || next.RemainingInput.Offset() <= result.RemainingInput.Offset() {
+			for savedKey, saved := range seed.saved {
+				if saved == nil {
+					delete(table.results, savedKey)
+				} else {
+					table.results[savedKey] = saved
+				}
+			}
+			result.Failure = furthestFailure(result.Failure, next.Failure)
+			entry.result = result
+			return result
+		}
+		result = next
+		entry.result = result
+	}
+}
+
+// memoTableOf returns the memoTable of the Input or nil if it can't memoize.
+func memoTableOf(Input Input) *memoTable {
+	var memoizing, isMemoizing = Input.(memoizingInput)
+	if !isMemoizing {
+		return nil
+	}
+	var table = memoizing.memoTable()
+	if table != nil && table.results == nil {
+		table.results = make(map[memoKey]*memoEntry)
+		table.seeds = make(map[int]*growingSeed)
+	}
+	return table
+}
@@ -0,0 +1,176 @@
+package parser
+
+import "math"
+
+// ChainLeft parses the following grammar: Chain := Operand ^ (Operator ^ Operand)*
+//
+// The operator parser produces the function that combines the operands to its
+// left and to its right. ChainLeft applies these functions from left to right,
+// i. e. "a - b - c" results in (a - b) - c.
+func ChainLeft[T any](operand Parser[T], operator Parser[func(T, T) T]) Parser[T] {
+	return Map(AndThen(operand, Many(AndThen(operator, operand))),
+		func(chain Pair[T, []Pair[func(T, T) T, T]]) T {
+			var result = chain.First
+			for _, next := range chain.Second {
+				result = next.First(result, next.Second)
+			}
+			return result
+		})
+}
+
+// ChainRight parses the same grammar as ChainLeft but applies the functions
+// from right to left, i. e. "a -> b -> c" results in a -> (b -> c).
+func ChainRight[T any](operand Parser[T], operator Parser[func(T, T) T]) Parser[T] {
+	return Map(AndThen(operand, Many(AndThen(operator, operand))),
+		func(chain Pair[T, []Pair[func(T, T) T, T]]) T {
+			if len(chain.Second) == 0 {
+				return chain.First
+			}
+			var last = len(chain.Second) - 1
+			var result = chain.Second[last].Second
+			for index := last; index > 0; index-- {
+				result = chain.Second[index].First(chain.Second[index-1].Second, result)
+			}
+			return chain.Second[0].First(chain.First, result)
+		})
+}
+
+// Associativity tells how infix operators of the same precedence are grouped.
+type Associativity int
+
+const (
+
+	// LeftAssociative operators are grouped from left to right, i. e.
+	// "a - b - c" results in (a - b) - c.
+	LeftAssociative Associativity = iota
+
+	// RightAssociative operators are grouped from right to left, i. e.
+	// "a -> b -> c" results in a -> (b -> c).
+	RightAssociative
+)
+
+// Operator declares an operator for OperatorPrecedence. Use InfixLeft,
+// InfixRight and Prefix to create operators.
+type Operator[T any] struct {
+
+	// Symbol parses the operator itself, e.g. ExpectString("&").
+	Symbol Parser[string]
+
+	// Precedence tells how tightly the operator binds. Operators with a higher
+	// precedence bind tighter than operators with a lower precedence.
+	Precedence int
+
+	// Associativity groups infix operators of the same precedence.
+	Associativity Associativity
+
+	// Infix combines the operands of an infix operator. It's nil for prefix
+	// operators.
+	Infix func(T, T) T
+
+	// Prefix applies a prefix operator to its operand. It's nil for infix
+	// operators.
+	Prefix func(T) T
+}
+
+// InfixLeft declares a left-associative infix operator.
+func InfixLeft[T any](symbol Parser[string], precedence int, infix func(T, T) T) Operator[T] {
+	return Operator[T]{symbol, precedence, LeftAssociative, infix, nil}
+}
+
+// InfixRight declares a right-associative infix operator.
+func InfixRight[T any](symbol Parser[string], precedence int, infix func(T, T) T) Operator[T] {
+	return Operator[T]{symbol, precedence, RightAssociative, infix, nil}
+}
+
+// Prefix declares a prefix operator. Its operand may contain operators of
+// the same or higher precedence only, i. e. a prefix operator with the highest
+// precedence applies to the next operand alone.
+func Prefix[T any](symbol Parser[string], precedence int, prefix func(T) T) Operator[T] {
+	return Operator[T]{symbol, precedence, LeftAssociative, nil, prefix}
+}
+
+// OperatorPrecedence creates a parser for expressions made of operands and the
+// operators from the table. It's a Pratt parser, also known as precedence
+// climbing: the operators are declared along with their precedence and
+// associativity instead of writing one grammar rule per precedence level.
+// The symbols are tried in the order of the operators, so if one symbol is a
+// prefix of another then declare the longer one first.
+func OperatorPrecedence[T any](operand Parser[T], operators ...Operator[T]) Parser[T] {
+	var table = operatorTable[T]{operand, operators}
+	return func(Input Input) Result[T] {
+		return table.parse(Input, math.MinInt)
+	}
+}
+
+// operatorTable holds everything that OperatorPrecedence needs to parse.
+type operatorTable[T any] struct {
+	operand   Parser[T]
+	operators []Operator[T]
+}
+
+// parse parses an expression that contains only operators with a precedence
+// of at least minPrecedence.
+func (table operatorTable[T]) parse(Input Input, minPrecedence int) Result[T] {
+	var left = table.parsePrefix(Input)
+	var failure = left.Failure
+	for left.Success {
+		var next = table.parseInfix(left, minPrecedence)
+		failure = furthestFailure(failure, next.Failure)
+		if !next.Success {
+			break
+		}
+		left = next
+	}
+	left.Failure = failure
+	return left
+}
+
+// parsePrefix parses an operand that may be preceded by a prefix operator.
+func (table operatorTable[T]) parsePrefix(Input Input) Result[T] {
+	var failure *Failure
+	for _, operator := range table.operators {
+		if operator.Prefix == nil {
+			continue
+		}
+		var symbol = operator.Symbol(Input)
+		failure = furthestFailure(failure, symbol.Failure)
+		if !symbol.Success {
+			continue
+		}
+		var operand = table.parse(symbol.RemainingInput, operator.Precedence)
+		failure = furthestFailure(failure, operand.Failure)
+		if operand.Success {
+			return succeed(operator.Prefix(operand.Result), operand.RemainingInput, failure)
+		}
+	}
+	var operand = table.operand(Input)
+	operand.Failure = furthestFailure(failure, operand.Failure)
+	return operand
+}
+
+// parseInfix tries to continue the left operand with an infix operator of at
+// least minPrecedence and its right operand.
+func (table operatorTable[T]) parseInfix(left Result[T], minPrecedence int) Result[T] {
+	var failure *Failure
+	for _, operator := range table.operators {
+		if operator.Infix == nil || operator.Precedence < minPrecedence {
+			continue
+		}
+		var symbol = operator.Symbol(left.RemainingInput)
+		failure = furthestFailure(failure, symbol.Failure)
+		if !symbol.Success {
+			continue
+		}
+		var rightPrecedence = operator.Precedence + 1
+		if operator.Associativity == RightAssociative {
+			rightPrecedence = operator.Precedence
+		}
+		var right = table.parse(symbol.RemainingInput, rightPrecedence)
+		failure = furthestFailure(failure, right.Failure)
+		if right.Success {
+			return succeed(operator.Infix(left.Result, right.Result),
+				right.RemainingInput, failure)
+		}
+	}
+	return fail[T](left.RemainingInput, failure)
+}
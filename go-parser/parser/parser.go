@@ -1,47 +1,56 @@
 package parser
 
 import (
-	"container/list"
+	"strconv"
 	"strings"
 )
 
-// Parser parses its Input and produces some result.
-type Parser func(Input) Result
-
-// Input is anything that can produce a sequence of code points.
-// RuneArrayInput is one implementation that you can use. See StringToInput
-// if you want to create Input directly from a string.
-type Input interface {
-
-	// CurrentCodePoint returns the rune at the beginning of this Input
-	CurrentCodePoint() rune
-
-	// RemainingInput returns everything that comes after the current code point.
-	RemainingInput() Input
-}
+// Parser parses its Input and produces a result of type T. The type parameter
+// makes the compiler check that parsers are combined in a meaningful way.
+type Parser[T any] func(Input) Result[T]
 
 // Result is the result of a parse along with the Input that remains to
 // be parsed.
-type Result struct {
+type Result[T any] struct {
+
+	// Result is the result of a successful parse. It's the zero value of T if
+	// parsing failed.
+	Result T
 
-	// Result can be anything except for nil which indicates that parsing failed.
-	// Parsing failed <==> Result == nil
-	Result interface{}
+	// Success is true if and only if parsing succeeded.
+	Success bool
 
 	// RemainingInput is the rest of the Input after the successful parse of
 	// Result. If the parse failed then it's just the Input from before the
 	// parsing attempt.
 	RemainingInput Input
+
+	// Failure describes the furthest failure that happened during the parse.
+	// It's always set if parsing failed. A successful parse may carry a Failure
+	// as well: it tells why the parser didn't consume more of the Input, which
+	// is what users want to know if the caller expected more to be consumed.
+	Failure *Failure
+}
+
+// succeed creates the Result of a successful parse.
+func succeed[T any](result T, RemainingInput Input, failure *Failure) Result[T] {
+	return Result[T]{result, true, RemainingInput, failure}
+}
+
+// fail creates the Result of a failed parse.
+func fail[T any](RemainingInput Input, failure *Failure) Result[T] {
+	var nothing T
+	return Result[T]{nothing, false, RemainingInput, failure}
 }
 
 // ExpectCodePoint expects exactly one rune in the Input. If the Input
 // starts with this rune it will become the result.
-func ExpectCodePoint(expectedCodePoint rune) Parser {
-	return func(Input Input) Result {
-		if expectedCodePoint == Input.CurrentCodePoint() {
-			return Result{expectedCodePoint, Input.RemainingInput()}
+func ExpectCodePoint(expectedCodePoint rune) Parser[rune] {
+	return func(Input Input) Result[rune] {
+		if !Input.AtEnd() && expectedCodePoint == Input.CurrentCodePoint() {
+			return succeed(expectedCodePoint, Input.RemainingInput(), nil)
 		}
-		return Result{nil, Input}
+		return fail[rune](Input, failureAt(Input, quote(expectedCodePoint)))
 	}
 }
 
@@ -49,51 +58,49 @@ func ExpectCodePoint(expectedCodePoint rune) Parser {
 // expectedCodePoints at the beginning of the Input in the given order.
 // If the Input begins with these code points then expectedCodePoints will
 // be the result of the parse.
-func ExpectCodePoints(expectedCodePoints []rune) Parser {
-	return func(Input Input) Result {
+// A failure is always reported at the beginning of the Input and expects all of
+// expectedCodePoints.
+func ExpectCodePoints(expectedCodePoints []rune) Parser[[]rune] {
+	return func(Input Input) Result[[]rune] {
 		var RemainingInput = Input
 		for _, expectedCodePoint := range expectedCodePoints {
-			if nil == RemainingInput {
-				return Result{nil, RemainingInput}
-			}
 			var result = ExpectCodePoint(expectedCodePoint)(RemainingInput)
-			if result.Result == nil {
-				return Result{nil, RemainingInput}
+			if !result.Success {
+				return fail[[]rune](RemainingInput,
+					failureAt(Input, quote(expectedCodePoints...)))
 			}
 			RemainingInput = result.RemainingInput
 		}
-		return Result{expectedCodePoints, RemainingInput}
+		return succeed(expectedCodePoints, RemainingInput, nil)
 	}
 }
 
 // ExpectString expects the Input to begin with the code points from the
 // expectedString in the given order. If the Input starts with these code
 // points then expectedString will be the result of the parse.
-func ExpectString(expectedString string) Parser {
-	return func(Input Input) Result {
-		var result = ExpectCodePoints([]rune(expectedString))(Input)
-		var runes, isRuneArray = result.Result.([]rune)
-		if isRuneArray {
-			result.Result = string(runes)
-		}
-		return result
-	}
+func ExpectString(expectedString string) Parser[string] {
+	return Map(ExpectCodePoints([]rune(expectedString)), func(runes []rune) string {
+		return string(runes)
+	})
 }
 
-// Repeated applies a parser zero or more times and accumulates the results
-// of the parses in a list. This parse always produces a non-nil result.
-func (parser Parser) Repeated() Parser {
-	return func(Input Input) Result {
-		var result = Result{list.New(), Input}
-		for result.RemainingInput != nil {
+// Many applies a parser zero or more times and accumulates the results
+// of the parses in a slice. This parse always succeeds. It stops as soon as
+// the parser fails or succeeds without consuming any Input, because the
+// parser would succeed that way forever.
+func Many[T any](parser Parser[T]) Parser[[]T] {
+	return func(Input Input) Result[[]T] {
+		var result = succeed([]T{}, Input, nil)
+		for {
 			var oneMoreResult = parser(result.RemainingInput)
-			if oneMoreResult.Result == nil {
+			result.Failure = furthestFailure(result.Failure, oneMoreResult.Failure)
+			if !oneMoreResult.Success ||
+				oneMoreResult.RemainingInput.Offset() == result.RemainingInput.Offset() {
 				return result
 			}
-			result.Result.(*list.List).PushBack(oneMoreResult.Result)
+			result.Result = append(result.Result, oneMoreResult.Result)
 			result.RemainingInput = oneMoreResult.RemainingInput
 		}
-		return result
 	}
 }
 
@@ -104,145 +111,175 @@ func (parser Parser) Repeated() Parser {
 // then it will not attempt to use the second parser and there's no
 // back-tracking. This is in contrast to most regex-libs where the longest
 // match wins. The first match wins here, please keep this in mind.
-func (parser Parser) OrElse(alternativeParser Parser) Parser {
-	return func(Input Input) Result {
+// Use Longest if the parsers overlap.
+// If both parsers fail, the failure that got further into the Input wins.
+func (parser Parser[T]) OrElse(alternativeParser Parser[T]) Parser[T] {
+	return func(Input Input) Result[T] {
 		var FirstResult = parser(Input)
-		if FirstResult.Result != nil {
+		if FirstResult.Success {
 			return FirstResult
 		}
-		return alternativeParser(Input)
+		var alternativeResult = alternativeParser(Input)
+		alternativeResult.Failure = furthestFailure(FirstResult.Failure,
+			alternativeResult.Failure)
+		return alternativeResult
+	}
+}
+
+// Longest applies all the alternatives to the same Input and picks the result
+// of the successful one that consumed the most code points. If several
+// alternatives consume the same amount, the one that comes first wins. Unlike
+// OrElse it's fine to use overlapping parsers with Longest, e.g. a keyword like
+// "not" and identifiers that start with it like "nothing". If all the
+// alternatives fail, the failure that got furthest into the Input wins.
+func Longest[T any](alternatives ...Parser[T]) Parser[T] {
+	return func(Input Input) Result[T] {
+		var longest = fail[T](Input, nil)
+		var failure *Failure
+		for _, alternative := range alternatives {
+			var result = alternative(Input)
+			failure = furthestFailure(failure, result.Failure)
+			if result.Success && (!longest.Success ||
+				result.RemainingInput.Offset() > longest.RemainingInput.Offset()) {
+				longest = result
+			}
+		}
+		longest.Failure = failure
+		return longest
 	}
 }
 
 // Pair is a simple pair. Please use it only as an intermediate data structure.
 // If you know what you're parsing then convert your pairs into structs with
 // more meaningful names.
-type Pair struct {
+type Pair[A any, B any] struct {
 
 	// First is the first component of the pair.
-	First interface{}
+	First A
 
 	// Second is the second component of the pair.
-	Second interface{}
-}
-
-// GetSecond extracts the second component of a pair or
-// returns the argument if it is not a pair.
-func GetSecond(argument interface{}) interface{} {
-	var pair, isPair = argument.(Pair)
-	if isPair {
-		return pair.Second
-	}
-	return argument
-}
-
-// GetFirst extracts the first component of a pair or
-// returns the argument if it is not a pair.
-func GetFirst(argument interface{}) interface{} {
-	var pair, isPair = argument.(Pair)
-	if isPair {
-		return pair.First
-	}
-	return argument
+	Second B
 }
 
 // AndThen applies the firstParser to the Input and then the
 // secondParser. The result will be a Pair containing the results
 // of both parsers.
-func (parser Parser) AndThen(secondParser Parser) Parser {
-	return func(Input Input) Result {
-		var firstResult = parser(Input)
-		if firstResult.Result != nil {
-			var secondResult = secondParser(firstResult.RemainingInput)
-			if secondResult.Result != nil {
-				return Result{
-					Pair{firstResult.Result, secondResult.Result},
-					secondResult.RemainingInput}
-			}
-			return secondResult
+func AndThen[A any, B any](firstParser Parser[A], secondParser Parser[B]) Parser[Pair[A, B]] {
+	return func(Input Input) Result[Pair[A, B]] {
+		var firstResult = firstParser(Input)
+		if !firstResult.Success {
+			return fail[Pair[A, B]](firstResult.RemainingInput, firstResult.Failure)
 		}
-		return firstResult
+		var secondResult = secondParser(firstResult.RemainingInput)
+		var failure = furthestFailure(firstResult.Failure, secondResult.Failure)
+		if !secondResult.Success {
+			return fail[Pair[A, B]](secondResult.RemainingInput, failure)
+		}
+		return succeed(Pair[A, B]{firstResult.Result, secondResult.Result},
+			secondResult.RemainingInput, failure)
 	}
 }
 
-// Convert applies the converter to the result of a successful parse.
-// If the parser fails then Convert won't do anything.
-func (parser Parser) Convert(
-	converter func(interface{}) interface{}) Parser {
-	return func(Input Input) Result {
+// Map applies the converter to the result of a successful parse.
+// If the parser fails then Map won't do anything.
+func Map[T any, U any](parser Parser[T], converter func(T) U) Parser[U] {
+	return func(Input Input) Result[U] {
 		var result = parser(Input)
-		if result.Result != nil {
-			result.Result = converter(result.Result)
+		if !result.Success {
+			return fail[U](result.RemainingInput, result.Failure)
 		}
-		return result
+		return succeed(converter(result.Result), result.RemainingInput, result.Failure)
 	}
 }
 
 // First extracts the first component of the result of a successful parse.
 // If the parser fails then First won't do anything.
-func (parser Parser) First() Parser {
-	return parser.Convert(GetFirst)
+func First[A any, B any](parser Parser[Pair[A, B]]) Parser[A] {
+	return Map(parser, func(pair Pair[A, B]) A {
+		return pair.First
+	})
 }
 
 // Second extracts the second component of the result of a successful parse.
 // If the parser fails then Second won't do anything.
-func (parser Parser) Second() Parser {
-	return parser.Convert(GetSecond)
+func Second[A any, B any](parser Parser[Pair[A, B]]) Parser[B] {
+	return Map(parser, func(pair Pair[A, B]) B {
+		return pair.Second
+	})
 }
 
-// Nothing is the result of successfully parsing nothing at all.
-// Don't confuse it with nil which indicates failure.
-// This Nothing type means that the parser has explicitly allowed
-// an empty Input to be valid and to produce this result!
-type Nothing struct{}
+// Located is the result of Locate: the result of a parser along with the
+// Input where the parser started and the Input that remained after it.
+type Located[T any] struct {
 
-// Optional applies the parser zero or one times to the Input.
-// If the parser itself would fail then the Optional parser can still
-// produce a successful parse with the result Nothing{}.
-func (parser Parser) Optional() Parser {
-	return func(Input Input) Result {
+	// Result is the result of the parser.
+	Result T
+
+	// Start is the Input that the parser started with.
+	Start Input
+
+	// End is the Input that remained after the parser.
+	End Input
+}
+
+// Locate records where the parser started and ended in the Input. Use it to
+// find out where the results of a parse come from in the text.
+func Locate[T any](parser Parser[T]) Parser[Located[T]] {
+	return func(Input Input) Result[Located[T]] {
 		var result = parser(Input)
-		if result.Result == nil {
-			result.Result = Nothing{}
-			result.RemainingInput = Input
+		if !result.Success {
+			return fail[Located[T]](result.RemainingInput, result.Failure)
 		}
-		return result
+		return succeed(Located[T]{result.Result, Input, result.RemainingInput},
+			result.RemainingInput, result.Failure)
 	}
 }
 
-// RuneArrayInput is an implementation of Input.
-// You can use StringToInput to create instances of this type directly
-// from strings.
-type RuneArrayInput struct {
+// Nothing is the result of successfully parsing nothing at all, e.g. the end
+// of the Input.
+type Nothing struct{}
 
-	// Text is the whole Input text. Please keep it unchanged while parsers are
-	// working on it.
-	Text []rune
+// Option is the result of Optional. It either holds a Value or it's empty.
+type Option[T any] struct {
 
-	// CurrentPosition points to the current code point in the Text
-	CurrentPosition int
+	// Value is the value if the Option isn't empty.
+	Value T
+
+	// Present is true if and only if the Option isn't empty.
+	Present bool
 }
 
-// RemainingInput is necessary for RuneArrayInput to implement Input
-func (Input RuneArrayInput) RemainingInput() Input {
-	if Input.CurrentPosition+1 >= len(Input.Text) {
-		return nil
-	}
-	return RuneArrayInput{Input.Text, Input.CurrentPosition + 1}
+// Some creates an Option holding the value.
+func Some[T any](value T) Option[T] {
+	return Option[T]{value, true}
 }
 
-// CurrentCodePoint is necessary for RuneArrayInput to implement Input.
-func (Input RuneArrayInput) CurrentCodePoint() rune {
-	if Input.CurrentPosition >= len(Input.Text) {
-		return '\x00' // only happens with empty Input now?!
-	}
+// None creates an empty Option.
+func None[T any]() Option[T] {
+	return Option[T]{}
+}
 
-	return Input.Text[Input.CurrentPosition]
+// Optional applies the parser zero or one times to the Input.
+// If the parser itself would fail then the Optional parser can still
+// produce a successful parse with an empty Option. The failure is kept
+// in the Result for error messages.
+func Optional[T any](parser Parser[T]) Parser[Option[T]] {
+	return func(Input Input) Result[Option[T]] {
+		var result = parser(Input)
+		if !result.Success {
+			return succeed(None[T](), Input, result.Failure)
+		}
+		return succeed(Some(result.Result), result.RemainingInput, result.Failure)
+	}
 }
 
-// StringToInput converts a string to a RuneArrayInput so you can use parsers on it.
-func StringToInput(Text string) Input {
-	return RuneArrayInput{[]rune(Text), 0}
+// ExpectEnd succeeds with the result Nothing{} if there's no Input left.
+// Use it to make sure that a parser consumes the whole Input.
+var ExpectEnd Parser[Nothing] = func(Input Input) Result[Nothing] {
+	if Input.AtEnd() {
+		return succeed(Nothing{}, Input, nil)
+	}
+	return fail[Nothing](Input, failureAt(Input, "end of input"))
 }
 
 func isIdentifierStartChar(FirstCodePoint rune) bool {
@@ -270,39 +307,74 @@ func isSpaceChar(codePoint rune) bool {
 // first code point that doesn't satisfy isLaterChar. ExpectSeveral will only
 // fail if the first character from the Input doesn't satisfy isFirstChar!
 func ExpectSeveral(isFirstChar func(rune) bool,
-	isLaterChar func(rune) bool) Parser {
-	return func(Input Input) Result {
-		if nil == Input {
-			return Result{nil, Input}
-		}
+	isLaterChar func(rune) bool) Parser[string] {
+	return func(Input Input) Result[string] {
 		var FirstCodePoint = Input.CurrentCodePoint()
-		if !isFirstChar(FirstCodePoint) {
-			return Result{nil, Input}
+		if Input.AtEnd() || !isFirstChar(FirstCodePoint) {
+			return fail[string](Input, failureAt(Input))
 		}
 		var builder strings.Builder
 		var codePoint = FirstCodePoint
 		var RemainingInput = Input
-		for isLaterChar(codePoint) {
+		for !RemainingInput.AtEnd() && isLaterChar(codePoint) {
 			builder.WriteRune(codePoint)
 			RemainingInput = RemainingInput.RemainingInput()
-			if RemainingInput == nil {
-				break
-			} else {
-				codePoint = RemainingInput.CurrentCodePoint()
-			}
+			codePoint = RemainingInput.CurrentCodePoint()
+		}
+		return succeed(builder.String(), RemainingInput, nil)
+	}
+}
+
+// ExpectIdentifier parses a [a-zA-Z_][a-zA-Z0-9_]* from the Input. Use
+// ExpectIdentifierExcept if some identifiers are reserved words.
+var ExpectIdentifier Parser[string] = ExpectSeveral(isIdentifierStartChar, isIdentifierChar).Named("identifier")
+
+// ExpectIdentifierExcept parses an identifier like ExpectIdentifier but fails
+// if isReserved returns true for it. This keeps keywords like "and" from being
+// taken for identifiers while identifiers like "android" are still accepted.
+// The Failure expects an identifier at the beginning of the Input.
+func ExpectIdentifierExcept(isReserved func(string) bool) Parser[string] {
+	return func(Input Input) Result[string] {
+		var result = ExpectIdentifier(Input)
+		if result.Success && isReserved(result.Result) {
+			return fail[string](Input, failureAt(Input, "identifier"))
 		}
-		return Result{builder.String(), RemainingInput}
+		return result
 	}
 }
 
-// ExpectIdentifier parses a [a-zA-Z_][a-zA-Z0-9_]* from the Input.
-var ExpectIdentifier Parser = ExpectSeveral(isIdentifierStartChar, isIdentifierChar)
+// ExpectKeyword expects the word at the beginning of the Input. Unlike
+// ExpectString it fails if an identifier character follows the word, i. e.
+// the keyword "and" doesn't match the beginning of "android". If ignoreCase is
+// true then the case of the letters doesn't matter, e.g. "AND" and "And" match
+// the keyword "and", too. The result of the parse is the word as it appears in
+// the Input.
+// A failure is always reported at the beginning of the Input and expects the
+// word.
+func ExpectKeyword(word string, ignoreCase bool) Parser[string] {
+	var length = len([]rune(word))
+	return func(Input Input) Result[string] {
+		var builder strings.Builder
+		var RemainingInput = Input
+		for index := 0; index < length && !RemainingInput.AtEnd(); index++ {
+			builder.WriteRune(RemainingInput.CurrentCodePoint())
+			RemainingInput = RemainingInput.RemainingInput()
+		}
+		var text = builder.String()
+		if text != word && !(ignoreCase && strings.EqualFold(text, word)) ||
+			isIdentifierChar(RemainingInput.CurrentCodePoint()) {
+			return fail[string](Input, failureAt(Input, strconv.Quote(word)))
+		}
+		return succeed(text, RemainingInput, nil)
+	}
+}
 
-// ExpectSpaces parses a [ \t\n\r]* from the Input.
-var ExpectSpaces Parser = ExpectSeveral(isSpaceChar, isSpaceChar).Optional()
+// ExpectSpaces parses a [ \t\n\r]* from the Input. Spaces never show up in
+// the expectations of a Failure.
+var ExpectSpaces Parser[Option[string]] = Optional(ExpectSeveral(isSpaceChar, isSpaceChar).Named(""))
 
 // MaybeSpacesBefore allows and ignores space characters before applying the
 // parser from the argument.
-func MaybeSpacesBefore(parser Parser) Parser {
-	return Parser(ExpectSpaces).AndThen(parser).Second()
+func MaybeSpacesBefore[T any](parser Parser[T]) Parser[T] {
+	return Second(AndThen(ExpectSpaces, parser))
 }
@@ -0,0 +1,384 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestFailureOfExpectString(t *testing.T) {
+	var result = ExpectString("->")(StringToInput("-x"))
+	var expected = &Failure{Offset: 0, Expected: []string{"\"->\""}}
+	if result.Success || !reflect.DeepEqual(result.Failure, expected) {
+		t.Errorf("ExpectString(\"->\") on input \"-x\" must fail with %v "+
+			"but got %v with failure %v !", expected, result.Result, result.Failure)
+	}
+}
+
+func TestFurthestFailureWins(t *testing.T) {
+	var abc = Second(AndThen(ExpectString("a"), ExpectString("bc")))
+	var abd = Second(AndThen(ExpectString("a"), ExpectString("bd")))
+	var result = abc.OrElse(ExpectString("x")).OrElse(abd)(StringToInput("abx"))
+	var expected = &Failure{Offset: 1, Expected: []string{"\"bc\"", "\"bd\""}}
+	if !reflect.DeepEqual(result.Failure, expected) {
+		t.Errorf("OrElse must merge the furthest failures! "+
+			"Expected %v but got %v !", expected, result.Failure)
+	}
+
+	result = Second(AndThen(ExpectString("a"), ExpectString("b")))(StringToInput("a"))
+	expected = &Failure{Offset: 1, EndOfInput: true, Expected: []string{"\"b\""}}
+	if !reflect.DeepEqual(result.Failure, expected) {
+		t.Errorf("AndThen must fail at the end of the input! "+
+			"Expected %v but got %v !", expected, result.Failure)
+	}
+}
+
+func TestNamed(t *testing.T) {
+	var result = MaybeSpacesBefore(ExpectIdentifier)(StringToInput("  1"))
+	var expected = &Failure{Offset: 2, Expected: []string{"identifier"}}
+	if !reflect.DeepEqual(result.Failure, expected) {
+		t.Errorf("ExpectIdentifier must be named \"identifier\" in failures! "+
+			"Expected %v but got %v !", expected, result.Failure)
+	}
+
+	var named = Second(AndThen(ExpectString("a"), ExpectString("b"))).Named("ab")
+	result = named(StringToInput("ax"))
+	expected = &Failure{Offset: 1, Expected: []string{"\"b\""}}
+	if !reflect.DeepEqual(result.Failure, expected) {
+		t.Errorf("Named mustn't hide failures after the beginning of the "+
+			"input! Expected %v but got %v !", expected, result.Failure)
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	var and = ExpectKeyword("and", true)
+	for _, text := range []string{"and", "AND b", "And(", "and\n"} {
+		var result = and(StringToInput(text))
+		if !result.Success || !strings.EqualFold(result.Result, "and") {
+			t.Errorf("ExpectKeyword on input %q failed! Expected \"and\" "+
+				"but got wrong result %v !", text, result.Result)
+		}
+	}
+	for _, text := range []string{"android", "an", "and_", "or"} {
+		var result = and(StringToInput(text))
+		var expected = &Failure{Offset: 0, Expected: []string{"\"and\""}}
+		if result.Success || !reflect.DeepEqual(result.Failure, expected) {
+			t.Errorf("ExpectKeyword on input %q must fail with %v but got %v !",
+				text, expected, result.Failure)
+		}
+	}
+	if ExpectKeyword("and", false)(StringToInput("AND")).Success {
+		t.Errorf("ExpectKeyword mustn't ignore the case unless asked to!")
+	}
+
+	var identifier = ExpectIdentifierExcept(func(name string) bool {
+		return strings.EqualFold(name, "and")
+	})
+	var result = identifier(StringToInput("android"))
+	if !result.Success || result.Result != "android" {
+		t.Errorf("ExpectIdentifierExcept on input \"android\" failed! "+
+			"Expected android but got wrong result %v !", result.Result)
+	}
+	result = identifier(StringToInput("And b"))
+	var expected = &Failure{Offset: 0, Expected: []string{"identifier"}}
+	if result.Success || !reflect.DeepEqual(result.Failure, expected) {
+		t.Errorf("ExpectIdentifierExcept must reject reserved words! "+
+			"Expected %v but got %v !", expected, result.Failure)
+	}
+}
+
+func TestMany(t *testing.T) {
+	var result = Many(ExpectCodePoint('a'))(StringToInput("aab"))
+	var expected = []rune{'a', 'a'}
+	if !result.Success || !reflect.DeepEqual(result.Result, expected) ||
+		result.RemainingInput.Offset() != 2 {
+		t.Errorf("Many(ExpectCodePoint('a')) on input \"aab\" failed! "+
+			"Expected %v but got wrong result %v !", expected, result.Result)
+	}
+	result = Many(ExpectCodePoint('a'))(StringToInput("b"))
+	if !result.Success || len(result.Result) != 0 {
+		t.Errorf("Many must succeed without any match but got %v !", result)
+	}
+}
+
+func TestOptionalAndMap(t *testing.T) {
+	var length = Map(Optional(ExpectIdentifier), func(name Option[string]) int {
+		return len(name.Value)
+	})
+	var result = length(StringToInput("abc"))
+	if !result.Success || result.Result != 3 {
+		t.Errorf("Map(Optional(ExpectIdentifier)) on input \"abc\" failed! "+
+			"Expected 3 but got wrong result %v !", result.Result)
+	}
+	result = length(StringToInput("!"))
+	if !result.Success || result.Result != 0 || result.RemainingInput.Offset() != 0 {
+		t.Errorf("Map(Optional(ExpectIdentifier)) on input \"!\" failed! "+
+			"Expected 0 but got wrong result %v !", result.Result)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	var input = RuneArrayInput{Text: []rune("ab\ncd"), CurrentPosition: 4}
+	var expected = Position{Offset: 4, Line: 2, Column: 2}
+	if input.Position() != expected {
+		t.Errorf("Position of \"d\" in \"ab\\ncd\" is wrong! Expected %v "+
+			"but got %v !", expected, input.Position())
+	}
+}
+
+// number parses a decimal number as an int.
+var number = Map(ExpectSeveral(isDigit, isDigit), func(text string) int {
+	var value = 0
+	for _, codePoint := range text {
+		value = 10*value + int(codePoint-'0')
+	}
+	return value
+})
+
+func operator(symbol string, apply func(int, int) int) Parser[func(int, int) int] {
+	return Map(ExpectString(symbol), func(string) func(int, int) int { return apply })
+}
+
+func subtract(lhs int, rhs int) int { return lhs - rhs }
+
+func power(lhs int, rhs int) int {
+	var result = 1
+	for index := 0; index < rhs; index++ {
+		result *= lhs
+	}
+	return result
+}
+
+func testArithmetic(t *testing.T, name string, parser Parser[int], text string, expected int) {
+	var result = parser(StringToInput(text))
+	if !result.Success || result.Result != expected || !result.RemainingInput.AtEnd() {
+		t.Errorf("%v on input \"%v\" failed! Expected %v but got wrong result "+
+			"%v with failure %v !", name, text, expected, result.Result, result.Failure)
+	}
+}
+
+func TestChainLeftAndChainRight(t *testing.T) {
+	var left = ChainLeft(number, operator("-", subtract))
+	testArithmetic(t, "ChainLeft", left, "9-3-2", 4)
+	testArithmetic(t, "ChainLeft", left, "7", 7)
+	var right = ChainRight(number, operator("^", power))
+	testArithmetic(t, "ChainRight", right, "2^3^2", 512)
+	testArithmetic(t, "ChainRight", right, "2^3", 8)
+}
+
+func TestOperatorPrecedence(t *testing.T) {
+	var expression = OperatorPrecedence(number,
+		InfixLeft(ExpectString("-"), 1, subtract),
+		InfixLeft(ExpectString("*"), 2, func(lhs int, rhs int) int { return lhs * rhs }),
+		InfixRight(ExpectString("^"), 3, power),
+		Prefix(ExpectString("~"), 4, func(operand int) int { return -operand }))
+	testArithmetic(t, "OperatorPrecedence", expression, "9-3-2", 4)
+	testArithmetic(t, "OperatorPrecedence", expression, "9-3*2", 3)
+	testArithmetic(t, "OperatorPrecedence", expression, "2*3^2", 18)
+	testArithmetic(t, "OperatorPrecedence", expression, "2^3^2", 512)
+	testArithmetic(t, "OperatorPrecedence", expression, "~2-3", -5)
+	testArithmetic(t, "OperatorPrecedence", expression, "~~2^2", 4)
+
+	var result = expression(StringToInput("2*"))
+	var expected = &Failure{Offset: 2, EndOfInput: true, Expected: []string{"\"~\""}}
+	if !result.Success || result.Result != 2 || !reflect.DeepEqual(result.Failure, expected) {
+		t.Errorf("OperatorPrecedence must stop before an operator without "+
+			"operand! Got %v with failure %v !", result.Result, result.Failure)
+	}
+}
+
+// nested parses the following grammar: Nested := "(" Nested ")" "!" | "(" Nested ")" | "x"
+//
+// Without memoization every level parses the rest of the input twice, i. e.
+// parsing takes exponential time. It counts its calls in calls.
+func nested(calls *int, memoize func(Parser[string]) Parser[string]) Parser[string] {
+	var self Parser[string]
+	var inner Parser[string] = func(Input Input) Result[string] {
+		*calls++
+		var parenthesized = Second(First(AndThen(AndThen(ExpectString("("), self), ExpectString(")"))))
+		return First(AndThen(parenthesized, ExpectString("!"))).OrElse(parenthesized).OrElse(ExpectString("x"))(Input)
+	}
+	self = memoize(inner)
+	return self
+}
+
+func TestMemoize(t *testing.T) {
+	var text = strings.Repeat("(", 12) + "x" + strings.Repeat(")", 12)
+	var withoutMemo, withMemo int
+	var identity = func(parser Parser[string]) Parser[string] { return parser }
+	var plain = nested(&withoutMemo, identity)(StringToInput(text))
+	var memoized = nested(&withMemo, Memoize[string])(StringToInput(text))
+	if !plain.Success || !memoized.Success || plain.Result != memoized.Result ||
+		!memoized.RemainingInput.AtEnd() {
+		t.Fatalf("Memoize mustn't change the result! Expected %v but got %v !",
+			plain.Result, memoized.Result)
+	}
+	if withMemo != 13 || withoutMemo < 1<<12 {
+		t.Errorf("Memoize must parse every position once! Expected 13 calls "+
+			"but got %v, without Memoize there were %v calls !", withMemo, withoutMemo)
+	}
+
+	withMemo = 0
+	nested(&withMemo, Memoize[string])(RuneArrayInput{Text: []rune(text)})
+	if withMemo != withoutMemo {
+		t.Errorf("Memoize must be disabled for an Input without memoTable! "+
+			"Expected %v calls but got %v !", withoutMemo, withMemo)
+	}
+}
+
+// BenchmarkMemoize parses the grammar of nested with and without Memoize. The
+// time grows exponentially with the depth without Memoize and linearly with it.
+func BenchmarkMemoize(b *testing.B) {
+	var identity = func(parser Parser[string]) Parser[string] { return parser }
+	for _, depth := range []int{4, 8, 12} {
+		var text = strings.Repeat("(", depth) + "x" + strings.Repeat(")", depth)
+		for _, variant := range []struct {
+			name    string
+			memoize func(Parser[string]) Parser[string]
+		}{{"plain", identity}, {"memoized", Memoize[string]}} {
+			var calls int
+			var parser = nested(&calls, variant.memoize)
+			b.Run(fmt.Sprintf("%s/depth=%d", variant.name, depth), func(b *testing.B) {
+				for n := 0; n < b.N; n++ {
+					if !parser(StringToInput(text)).Success {
+						b.Fatal("nested failed")
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestLeftRecursion(t *testing.T) {
+	// Difference := Difference "-" Number | Number
+	var difference Parser[int]
+	difference = Memoize(func(Input Input) Result[int] {
+		return Map(AndThen(First(AndThen(difference, ExpectString("-"))), number),
+			func(pair Pair[int, int]) int { return pair.First - pair.Second },
+		).OrElse(number)(Input)
+	})
+	testArithmetic(t, "direct left recursion", difference, "9-3-2", 4)
+	testArithmetic(t, "direct left recursion", difference, "9", 9)
+
+	// Expression := Sum
+	// Sum        := Expression "-" Product | Product
+	// Product    := Product "*" Number | Number
+	var expression, sum, product Parser[int]
+	expression = Memoize(func(Input Input) Result[int] {
+		return sum(Input)
+	})
+	sum = Memoize(func(Input Input) Result[int] {
+		return Map(AndThen(First(AndThen(expression, ExpectString("-"))), product),
+			func(pair Pair[int, int]) int { return pair.First - pair.Second },
+		).OrElse(product)(Input)
+	})
+	product = Memoize(func(Input Input) Result[int] {
+		return Map(AndThen(First(AndThen(product, ExpectString("*"))), number),
+			func(pair Pair[int, int]) int { return pair.First * pair.Second },
+		).OrElse(number)(Input)
+	})
+	testArithmetic(t, "indirect left recursion", expression, "9-2*3-1", 2)
+	testArithmetic(t, "indirect left recursion", expression, "2*3*4-1-1", 22)
+	testArithmetic(t, "indirect left recursion", expression, "7", 7)
+
+	var result = expression(StringToInput("9-x"))
+	var expected = &Failure{Offset: 2}
+	if !result.Success || result.Result != 9 || !reflect.DeepEqual(result.Failure, expected) {
+		t.Errorf("Left recursion must stop before \"-x\"! Got %v with "+
+			"failure %v !", result.Result, result.Failure)
+	}
+}
+
+func TestLongest(t *testing.T) {
+	var keyword = Map(ExpectString("not"), func(string) string { return "keyword" })
+	var identifier = Map(ExpectIdentifier, func(string) string { return "identifier" })
+	var notOrIdentifier = Longest(keyword, identifier)
+	for text, expected := range map[string]string{
+		"not": "keyword", "nothing": "identifier", "no": "identifier", "not!": "keyword"} {
+		var result = notOrIdentifier(StringToInput(text))
+		if !result.Success || result.Result != expected {
+			t.Errorf("Longest(keyword, identifier) on input \"%v\" failed! "+
+				"Expected %v but got wrong result %v !", text, expected, result.Result)
+		}
+	}
+	if keyword.OrElse(identifier)(StringToInput("nothing")).Result != "keyword" {
+		t.Errorf("OrElse must pick the first match!")
+	}
+
+	var result = notOrIdentifier(StringToInput("1"))
+	var expected = &Failure{Offset: 0, Expected: []string{"\"not\"", "identifier"}}
+	if result.Success || !reflect.DeepEqual(result.Failure, expected) {
+		t.Errorf("Longest on input \"1\" must fail with %v but got %v with "+
+			"failure %v !", expected, result.Result, result.Failure)
+	}
+}
+
+// positioned is implemented by all the Inputs of this package.
+type positioned interface {
+	Input
+	Position() Position
+}
+
+func TestInputs(t *testing.T) {
+	var text = "añ\n€x"
+	var expected = []Position{{0, 1, 1}, {1, 1, 2}, {2, 1, 3}, {3, 2, 1}, {4, 2, 2}}
+	var inputs = map[string]Input{
+		"RuneArrayInput": StringToInput(text),
+		"ByteSliceInput": BytesToInput([]byte(text)),
+		"ReaderInput":    ReaderToInput(strings.NewReader(text)),
+	}
+	for name, input := range inputs {
+		var codePoints []rune
+		for index := 0; !input.AtEnd(); index++ {
+			var position = input.(positioned).Position()
+			if position != expected[index] || input.Offset() != index {
+				t.Errorf("%v has the wrong position at offset %v! Expected %v "+
+					"but got %v !", name, index, expected[index], position)
+			}
+			codePoints = append(codePoints, input.CurrentCodePoint())
+			input = input.RemainingInput()
+		}
+		if string(codePoints) != text {
+			t.Errorf("%v produced the wrong code points! Expected %q but "+
+				"got %q !", name, text, string(codePoints))
+		}
+		var end = input.RemainingInput()
+		if !end.AtEnd() || end.Offset() != 5 || end.CurrentCodePoint() != EndOfInput {
+			t.Errorf("%v must stay at the end of the input but got offset %v "+
+				"and code point %v !", name, end.Offset(), end.CurrentCodePoint())
+		}
+	}
+}
+
+func TestReaderInput(t *testing.T) {
+	var text = strings.Repeat("a", 3*readerChunkSize+1)
+	var result = ExpectIdentifier(ReaderToInput(strings.NewReader(text + " rest")))
+	if !result.Success || result.Result != text || result.RemainingInput.Offset() != len(text) {
+		t.Errorf("ExpectIdentifier on a ReaderInput must read across chunks! "+
+			"Got %v code points with failure %v !", len(result.Result), result.Failure)
+	}
+
+	var failing = io.MultiReader(strings.NewReader("ab"), iotest.ErrReader(errors.New("broken")))
+	var input = ReaderToInput(failing)
+	result = ExpectIdentifier(input)
+	if !result.Success || result.Result != "ab" || !result.RemainingInput.AtEnd() {
+		t.Errorf("ReaderInput must end at a read error but got %v !", result.Result)
+	}
+	if err := input.(ReaderInput).Err(); err == nil || err.Error() != "broken" {
+		t.Errorf("ReaderInput must report the read error but got %v !", err)
+	}
+}
+
+func TestLocate(t *testing.T) {
+	var result = MaybeSpacesBefore(Locate(ExpectIdentifier))(StringToInput("  abc!"))
+	if !result.Success || result.Result.Result != "abc" ||
+		result.Result.Start.Offset() != 2 || result.Result.End.Offset() != 5 {
+		t.Errorf("Locate must record the start and the end of \"abc\" but got "+
+			"%v from %v to %v !", result.Result.Result, result.Result.Start.Offset(),
+			result.Result.End.Offset())
+	}
+}
@@ -0,0 +1,238 @@
+package sat
+
+import (
+	"fmt"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+)
+
+// Satisfiable returns true if there are values for the variables that make
+// the expression true. The model holds such values for all the variables of
+// the expression. It's nil if the expression isn't satisfiable.
+//
+// Satisfiable converts the expression to conjunctive normal form by the Tseitin
+// transformation, which introduces a helper variable for every operator so
+// that the CNF grows linearly with the expression instead of exponentially.
+// Then it searches for a model by DPLL, i. e. by backtracking with unit
+// propagation.
+func Satisfiable(node ast.Node) (model map[string]bool, satisfiable bool) {
+	var encoding = newEncoding()
+	encoding.assert(node)
+	var assignment = encoding.solve(make([]value, len(encoding.names)+1))
+	if assignment == nil {
+		return nil, false
+	}
+	model = make(map[string]bool)
+	for _, name := range ast.Vars(node) {
+		model[name] = assignment[encoding.variables[name]] == isTrue
+	}
+	return model, true
+}
+
+// Tautology returns true if the expression is true for all values of its
+// variables. Otherwise the counterexample holds values for the variables that
+// make the expression false.
+func Tautology(node ast.Node) (counterexample map[string]bool, tautology bool) {
+	var model, satisfiable = Satisfiable(ast.Not{Ex: node})
+	return model, !satisfiable
+}
+
+// Equivalent returns true if both expressions have the same value for all
+// values of their variables. Otherwise the counterexample holds values for the
+// variables of both expressions for which they differ.
+func Equivalent(a ast.Node, b ast.Node) (counterexample map[string]bool, equivalent bool) {
+	return Tautology(ast.Equiv{LHS: a, RHS: b})
+}
+
+// value is the value of a variable during the search.
+type value int8
+
+const (
+	unassigned value = iota
+	isTrue
+	isFalse
+)
+
+// encoding is the CNF of an expression. The variables of the CNF are numbered
+// from 1. A literal is the number of a variable or its negative for the
+// negated variable. A clause is a slice of literals.
+type encoding struct {
+	clauses [][]int
+
+	// names holds the names of the variables of the CNF by their number minus
+	// one. The helper variables of the Tseitin transformation have no name.
+	names []string
+
+	// variables holds the numbers of the variables of the expression.
+	variables map[string]int
+}
+
+// newEncoding creates an empty CNF.
+func newEncoding() *encoding {
+	return &encoding{variables: make(map[string]int)}
+}
+
+// newVariable adds a variable to the CNF and returns its number.
+func (e *encoding) newVariable(name string) int {
+	e.names = append(e.names, name)
+	return len(e.names)
+}
+
+// assert adds clauses to the CNF that make the expression true.
+func (e *encoding) assert(node ast.Node) {
+	e.clauses = append(e.clauses, []int{e.literal(node)})
+}
+
+// literal returns a literal that is equivalent to the node. It adds the
+// clauses that define the helper variable of an operator to the CNF.
+func (e *encoding) literal(node ast.Node) int {
+	switch node := node.(type) {
+	case ast.Spanned:
+		return e.literal(node.Ex)
+	case ast.Val:
+		var variable, found = e.variables[node.Name]
+		if !found {
+			variable = e.newVariable(node.Name)
+			e.variables[node.Name] = variable
+		}
+		return variable
+	case ast.Const:
+		var variable = e.newVariable("")
+		if node.Value {
+			e.clauses = append(e.clauses, []int{variable})
+		} else {
+			e.clauses = append(e.clauses, []int{-variable})
+		}
+		return variable
+	case ast.Not:
+		return -e.literal(node.Ex)
+	case ast.And:
+		// x <-> a & b
+		var a, b = e.literal(node.LHS), e.literal(node.RHS)
+		var x = e.newVariable("")
+		e.clauses = append(e.clauses, []int{-x, a}, []int{-x, b}, []int{x, -a, -b})
+		return x
+	case ast.Or:
+		return e.or(e.literal(node.LHS), e.literal(node.RHS))
+	case ast.Implies:
+		return e.or(-e.literal(node.LHS), e.literal(node.RHS))
+	case ast.Xor:
+		return e.xor(e.literal(node.LHS), e.literal(node.RHS))
+	case ast.Equiv:
+		return -e.xor(e.literal(node.LHS), e.literal(node.RHS))
+	}
+	panic(fmt.Sprintf("sat: unknown node %v", node))
+}
+
+// or returns a helper variable x with x <-> a | b.
+func (e *encoding) or(a int, b int) int {
+	var x = e.newVariable("")
+	e.clauses = append(e.clauses, []int{-x, a, b}, []int{x, -a}, []int{x, -b})
+	return x
+}
+
+// xor returns a helper variable x with x <-> a ^ b.
+func (e *encoding) xor(a int, b int) int {
+	var x = e.newVariable("")
+	e.clauses = append(e.clauses, []int{-x, a, b}, []int{-x, -a, -b},
+		[]int{x, -a, b}, []int{x, a, -b})
+	return x
+}
+
+// valueOf returns the value of the literal in the assignment.
+func valueOf(assignment []value, literal int) value {
+	if literal > 0 {
+		return assignment[literal]
+	}
+	switch assignment[-literal] {
+	case isTrue:
+		return isFalse
+	case isFalse:
+		return isTrue
+	}
+	return unassigned
+}
+
+// assign makes the literal true in the assignment.
+func assign(assignment []value, literal int) {
+	if literal > 0 {
+		assignment[literal] = isTrue
+	} else {
+		assignment[-literal] = isFalse
+	}
+}
+
+// solve searches an assignment that extends the given one and satisfies all
+// the clauses. It returns nil if there isn't any. The given assignment isn't
+// changed.
+func (e *encoding) solve(assignment []value) []value {
+	assignment = append([]value(nil), assignment...)
+	if !e.propagate(assignment) {
+		return nil
+	}
+	var branch = e.unassignedLiteral(assignment)
+	if branch == 0 {
+		return assignment
+	}
+	for _, literal := range []int{branch, -branch} {
+		var next = append([]value(nil), assignment...)
+		assign(next, literal)
+		if result := e.solve(next); result != nil {
+			return result
+		}
+	}
+	return nil
+}
+
+// propagate assigns the last literal of every clause whose other literals are
+// false until there's no such clause anymore. It returns false if a clause is
+// false.
+func (e *encoding) propagate(assignment []value) bool {
+	for changed := true; changed; {
+		changed = false
+		for _, clause := range e.clauses {
+			var open, last = 0, 0
+			var satisfied = false
+			for _, literal := range clause {
+				switch valueOf(assignment, literal) {
+				case isTrue:
+					satisfied = true
+				case unassigned:
+					open++
+					last = literal
+				}
+			}
+			switch {
+			case satisfied:
+			case open == 0:
+				return false
+			case open == 1:
+				assign(assignment, last)
+				changed = true
+			}
+		}
+	}
+	return true
+}
+
+// unassignedLiteral returns an unassigned literal of a clause that isn't
+// satisfied yet or 0 if all the clauses are satisfied.
+func (e *encoding) unassignedLiteral(assignment []value) int {
+	for _, clause := range e.clauses {
+		var candidate = 0
+		for _, literal := range clause {
+			var v = valueOf(assignment, literal)
+			if v == isTrue {
+				candidate = 0
+				break
+			}
+			if v == unassigned && candidate == 0 {
+				candidate = literal
+			}
+		}
+		if candidate != 0 {
+			return candidate
+		}
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package sat
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+)
+
+func TestSatisfiable(t *testing.T) {
+	for _, text := range []string{"a", "!a & b", "(a | b) & (!a | c) & (!b | !c) & !c",
+		"a ^ b ^ c ^ true", "(a -> b) & (b -> c) & a", "!(x <-> y) & (y | false)"} {
+		var node = boolparser.MustParse(text)
+		var model, satisfiable = Satisfiable(node)
+		if !satisfiable || !node.Eval(model) || len(model) != len(ast.Vars(node)) {
+			t.Errorf("Satisfiable on input \"%v\" failed! Expected a model for "+
+				"all variables but got %v !", text, model)
+		}
+	}
+	for _, text := range []string{"a & !a", "false", "(a | b) & (!a | b) & (a | !b) & (!a | !b)",
+		"(a -> b) & (b -> c) & a & !c", "(a <-> !a) | !true"} {
+		if model, satisfiable := Satisfiable(boolparser.MustParse(text)); satisfiable {
+			t.Errorf("Satisfiable on input \"%v\" must fail but got model %v !", text, model)
+		}
+	}
+}
+
+func TestTautologyAndEquivalent(t *testing.T) {
+	for _, text := range []string{"a | !a", "true", "(a -> b) <-> (!b -> !a)", "a ^ a ^ a ^ !a"} {
+		if counterexample, tautology := Tautology(boolparser.MustParse(text)); !tautology {
+			t.Errorf("Tautology on input \"%v\" failed! Got counterexample %v !", text, counterexample)
+		}
+	}
+	var node = boolparser.MustParse("a -> b")
+	var counterexample, tautology = Tautology(node)
+	if tautology || node.Eval(counterexample) {
+		t.Errorf("Tautology on input \"a -> b\" must fail with a counterexample but got %v !", counterexample)
+	}
+
+	var equivalent = [][]string{
+		{"!(a & b)", "!a | !b"},
+		{"a -> b -> c", "a & b -> c"},
+		{"a ^ b", "!(a <-> b)"},
+		{"a | (a & b)", "a"},
+	}
+	for _, pair := range equivalent {
+		if counterexample, equivalent := Equivalent(boolparser.MustParse(pair[0]), boolparser.MustParse(pair[1])); !equivalent {
+			t.Errorf("Equivalent on input \"%v\" and \"%v\" failed! Got counterexample %v !",
+				pair[0], pair[1], counterexample)
+		}
+	}
+	var a, b = boolparser.MustParse("(a -> b) -> c"), boolparser.MustParse("a -> (b -> c)")
+	counterexample, isEquivalent := Equivalent(a, b)
+	if isEquivalent || a.Eval(counterexample) == b.Eval(counterexample) {
+		t.Errorf("Equivalent on input \"%v\" and \"%v\" must fail with a counterexample but got %v !",
+			a, b, counterexample)
+	}
+}
+
+func TestLargeExpression(t *testing.T) {
+
+	// The pigeonhole principle: 5 pigeons don't fit in 4 holes. The CNF of
+	// the expression would be huge without the Tseitin transformation.
+	var node ast.Node = ast.Const{Value: true}
+	for pigeon := 0; pigeon < 5; pigeon++ {
+		var somewhere ast.Node = ast.Const{Value: false}
+		for hole := 0; hole < 4; hole++ {
+			somewhere = ast.Or{LHS: somewhere, RHS: ast.Val{Name: fmt.Sprintf("p%dh%d", pigeon, hole)}}
+			for other := 0; other < pigeon; other++ {
+				node = ast.And{LHS: node, RHS: ast.Not{Ex: ast.And{
+					LHS: ast.Val{Name: fmt.Sprintf("p%dh%d", pigeon, hole)},
+					RHS: ast.Val{Name: fmt.Sprintf("p%dh%d", other, hole)},
+				}}}
+			}
+		}
+		node = ast.And{LHS: node, RHS: somewhere}
+	}
+	if model, satisfiable := Satisfiable(node); satisfiable {
+		t.Errorf("Satisfiable must fail for the pigeonhole principle but got model %v !", model)
+	}
+}
@@ -0,0 +1,296 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+)
+
+// ErrTooLarge is returned by CNF and DNF if the normal form would have more
+// clauses than allowed.
+var ErrTooLarge = errors.New("normal form too large")
+
+// NNF converts the AST to negation normal form: the result contains only And,
+// Or, Val and Const nodes and Not nodes that are applied directly to a Val.
+// NNF removes double negations, pushes negations down to the variables by De
+// Morgan's laws and replaces Xor, Implies and Equiv by And, Or and Not, e.g.
+// "!(a & !b)" becomes "!a | b". Spans are dropped. Nodes of other packages
+// are kept like variables, i. e. a negation stays on top of them.
+//
+// Every Xor and Equiv doubles its operands, so the result may be considerably
+// larger than the AST if these operators are nested.
+func NNF(node ast.Node) ast.Node {
+	return nnf(node, false)
+}
+
+// nnf converts the node to negation normal form. If negated is true it
+// converts the negation of the node.
+func nnf(node ast.Node, negated bool) ast.Node {
+	switch node := node.(type) {
+	case ast.Spanned:
+		return nnf(node.Ex, negated)
+	case ast.Not:
+		return nnf(node.Ex, !negated)
+	case ast.Const:
+		return ast.Const{Value: node.Value != negated}
+	case ast.Val:
+		if negated {
+			return ast.Not{Ex: node}
+		}
+		return node
+	case ast.And:
+		if negated {
+			return ast.Or{LHS: nnf(node.LHS, true), RHS: nnf(node.RHS, true)}
+		}
+		return ast.And{LHS: nnf(node.LHS, false), RHS: nnf(node.RHS, false)}
+	case ast.Or:
+		if negated {
+			return ast.And{LHS: nnf(node.LHS, true), RHS: nnf(node.RHS, true)}
+		}
+		return ast.Or{LHS: nnf(node.LHS, false), RHS: nnf(node.RHS, false)}
+	case ast.Implies:
+		// a -> b is !a | b, its negation is a & !b.
+		if negated {
+			return ast.And{LHS: nnf(node.LHS, false), RHS: nnf(node.RHS, true)}
+		}
+		return ast.Or{LHS: nnf(node.LHS, true), RHS: nnf(node.RHS, false)}
+	case ast.Xor:
+		// a ^ b is (a & !b) | (!a & b), its negation is a <-> b.
+		return nnfEquiv(node.LHS, node.RHS, !negated)
+	case ast.Equiv:
+		return nnfEquiv(node.LHS, node.RHS, negated)
+	}
+	if negated {
+		return ast.Not{Ex: node}
+	}
+	return node
+}
+
+// nnfEquiv converts lhs <-> rhs to negation normal form, i. e. to
+// (lhs & rhs) | (!lhs & !rhs). If negated is true it converts lhs ^ rhs, i. e.
+// (lhs & !rhs) | (!lhs & rhs).
+func nnfEquiv(lhs ast.Node, rhs ast.Node, negated bool) ast.Node {
+	return ast.Or{
+		LHS: ast.And{LHS: nnf(lhs, false), RHS: nnf(rhs, negated)},
+		RHS: ast.And{LHS: nnf(lhs, true), RHS: nnf(rhs, !negated)},
+	}
+}
+
+// CNF converts the AST to conjunctive normal form, i. e. to an And of clauses
+// that are an Or of variables and negated variables, e.g. "(a & b) | c"
+// becomes "(a | c) & (b | c)". The clauses are simplified: clauses that are
+// always true and clauses that contain another clause are dropped, e.g.
+// "(a | b) & a" becomes "a". If no clause is left the result is true, if a
+// clause has no literals left the result is false.
+//
+// The conversion may blow up the expression exponentially, so it fails with
+// ErrTooLarge as soon as the normal form of the AST or of one of its
+// sub-expressions has more than limit clauses. It fails with another error if
+// the AST contains nodes of other packages.
+func CNF(node ast.Node, limit int) (ast.Node, error) {
+	var form = normalForm{conjunctive: true, limit: limit}
+	var clauses, err = form.clauses(NNF(node))
+	if err != nil {
+		return nil, err
+	}
+	return form.build(clauses), nil
+}
+
+// DNF converts the AST to disjunctive normal form, i. e. to an Or of clauses
+// that are an And of variables and negated variables, e.g. "(a | b) & c"
+// becomes "(a & c) | (b & c)". The clauses are simplified like the ones of
+// CNF: clauses that are always false and clauses that contain another clause
+// are dropped. If no clause is left the result is false, if a clause has no
+// literals left the result is true.
+//
+// The conversion may blow up the expression exponentially, so it fails with
+// ErrTooLarge as soon as the normal form of the AST or of one of its
+// sub-expressions has more than limit clauses. It fails with another error if
+// the AST contains nodes of other packages.
+func DNF(node ast.Node, limit int) (ast.Node, error) {
+	var form = normalForm{conjunctive: false, limit: limit}
+	var clauses, err = form.clauses(NNF(node))
+	if err != nil {
+		return nil, err
+	}
+	return form.build(clauses), nil
+}
+
+// literal is a variable or a negated variable.
+type literal struct {
+	name    string
+	negated bool
+}
+
+// clause is an Or of literals in conjunctive normal form and an And of
+// literals in disjunctive normal form. Every literal appears once.
+type clause []literal
+
+// normalForm converts trees in negation normal form to CNF or DNF.
+type normalForm struct {
+
+	// conjunctive is true for CNF and false for DNF.
+	conjunctive bool
+
+	// limit is the maximum number of clauses.
+	limit int
+}
+
+// clauses returns the clauses of a node in negation normal form. The outer
+// operator of the normal form, And for CNF, just collects the clauses of its
+// operands. The inner operator, Or for CNF, distributes over the outer one,
+// i. e. it combines every clause of its LHS with every clause of its RHS.
+// The limit applies to the clauses that are left after simplification.
+func (form normalForm) clauses(node ast.Node) ([]clause, error) {
+	switch node := node.(type) {
+	case ast.Const:
+		// The empty clause is false in CNF and true in DNF.
+		if node.Value != form.conjunctive {
+			return form.check([]clause{{}})
+		}
+		return nil, nil
+	case ast.Val:
+		return form.check([]clause{{literal{node.Name, false}}})
+	case ast.Not:
+		if val, isVal := node.Ex.(ast.Val); isVal {
+			return form.check([]clause{{literal{val.Name, true}}})
+		}
+	case ast.And, ast.Or:
+		return form.operator(node)
+	}
+	return nil, fmt.Errorf("transform: can't convert node %v of type %T to a normal form", node, node)
+}
+
+// operator returns the clauses of an And or an Or, see clauses.
+func (form normalForm) operator(node ast.Node) ([]clause, error) {
+	var operands = ast.Operands(node)
+	var lhs, err = form.clauses(operands[0])
+	if err != nil {
+		return nil, err
+	}
+	rhs, err := form.clauses(operands[1])
+	if err != nil {
+		return nil, err
+	}
+	var _, isAnd = node.(ast.And)
+	if isAnd == form.conjunctive {
+		return form.check(subsume(append(lhs, rhs...)))
+	}
+	var combined []clause
+	for _, left := range lhs {
+		for _, right := range rhs {
+			if merged, ok := merge(left, right); ok {
+				combined = append(combined, merged)
+			}
+		}
+	}
+	return form.check(subsume(combined))
+}
+
+// check fails with ErrTooLarge if there are more clauses than the limit.
+func (form normalForm) check(clauses []clause) ([]clause, error) {
+	if len(clauses) > form.limit {
+		return nil, fmt.Errorf("%w: more than %d clauses", ErrTooLarge, form.limit)
+	}
+	return clauses, nil
+}
+
+// build creates the tree for the clauses.
+func (form normalForm) build(clauses []clause) ast.Node {
+	var result ast.Node
+	for _, clause := range clauses {
+		var inner ast.Node
+		for _, literal := range clause {
+			var node ast.Node = ast.Val{Name: literal.name}
+			if literal.negated {
+				node = ast.Not{Ex: node}
+			}
+			inner = form.combine(inner, node, !form.conjunctive)
+		}
+		if inner == nil {
+			inner = ast.Const{Value: !form.conjunctive}
+		}
+		result = form.combine(result, inner, form.conjunctive)
+	}
+	if result == nil {
+		return ast.Const{Value: form.conjunctive}
+	}
+	return result
+}
+
+// combine joins lhs and rhs with And if and is true or with Or otherwise. If
+// lhs is nil the result is rhs.
+func (form normalForm) combine(lhs ast.Node, rhs ast.Node, and bool) ast.Node {
+	switch {
+	case lhs == nil:
+		return rhs
+	case and:
+		return ast.And{LHS: lhs, RHS: rhs}
+	}
+	return ast.Or{LHS: lhs, RHS: rhs}
+}
+
+// merge joins two clauses. It returns false if the result contains a variable
+// along with its negation, i. e. if the result is always true in CNF and
+// always false in DNF.
+func merge(left clause, right clause) (clause, bool) {
+	var merged = append(clause{}, left...)
+	for _, literal := range right {
+		if merged.contains(literal) {
+			continue
+		}
+		if merged.contains(negate(literal)) {
+			return nil, false
+		}
+		merged = append(merged, literal)
+	}
+	return merged, true
+}
+
+// negate returns the negation of the literal.
+func negate(l literal) literal {
+	return literal{l.name, !l.negated}
+}
+
+// contains returns true if the clause contains the literal.
+func (c clause) contains(l literal) bool {
+	for _, other := range c {
+		if other == l {
+			return true
+		}
+	}
+	return false
+}
+
+// subsumes returns true if every literal of c is in other, which makes other
+// redundant.
+func (c clause) subsumes(other clause) bool {
+	for _, literal := range c {
+		if !other.contains(literal) {
+			return false
+		}
+	}
+	return true
+}
+
+// subsume removes the clauses that contain another clause, which is the
+// absorption law: "a & (a | b)" is "a" in CNF and "a | (a & b)" is "a" in
+// DNF. Of two equal clauses the first one is kept.
+func subsume(clauses []clause) []clause {
+	var kept []clause
+	for index, candidate := range clauses {
+		var redundant = false
+		for other, c := range clauses {
+			if other != index && c.subsumes(candidate) &&
+				(len(c) < len(candidate) || other < index) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			kept = append(kept, candidate)
+		}
+	}
+	return kept
+}
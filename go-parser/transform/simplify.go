@@ -0,0 +1,204 @@
+package transform
+
+import "github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+
+// Simplify returns a smaller AST with the same value for all variables. It
+// applies the following laws until none of them applies anymore:
+//
+//	!!a         => a                      (double negation)
+//	a & true    => a, a & false => false  (identity, annihilation)
+//	a & a       => a                      (idempotence)
+//	a & !a      => false                  (complement)
+//	a & (a | b) => a                      (absorption)
+//
+// The laws for Or are the dual ones, e.g. a | (a & b) => a. Chains of And
+// and Or are searched as a whole, e.g. "a & b & !a" becomes false. Xor,
+// Implies and Equiv are simplified if an operand is a constant or both of
+// them are equal. Unlike NNF Simplify keeps the structure of the expression
+// otherwise. Spans are dropped.
+func Simplify(node ast.Node) ast.Node {
+	var result = ast.StripSpans(node)
+	for {
+		var next = ast.Rewrite(result, simplify)
+		if next == result {
+			return next
+		}
+		result = next
+	}
+}
+
+// simplify simplifies a node whose operands are simplified already.
+func simplify(node ast.Node) ast.Node {
+	switch n := node.(type) {
+	case ast.Not:
+		switch operand := n.Ex.(type) {
+		case ast.Not:
+			return operand.Ex
+		case ast.Const:
+			return ast.Const{Value: !operand.Value}
+		}
+	case ast.And:
+		return simplifyChain(node, true)
+	case ast.Or:
+		return simplifyChain(node, false)
+	case ast.Xor:
+		return simplifyBinary(n.LHS, n.RHS, node, false, func(a bool, b bool) bool { return a != b })
+	case ast.Equiv:
+		return simplifyBinary(n.LHS, n.RHS, node, true, func(a bool, b bool) bool { return a == b })
+	case ast.Implies:
+		if lhs, isConst := n.LHS.(ast.Const); isConst {
+			if lhs.Value {
+				return n.RHS
+			}
+			return ast.Const{Value: true}
+		}
+		if rhs, isConst := n.RHS.(ast.Const); isConst {
+			if rhs.Value {
+				return rhs
+			}
+			return simplify(ast.Not{Ex: n.LHS})
+		}
+		if n.LHS == n.RHS {
+			return ast.Const{Value: true}
+		}
+	}
+	return node
+}
+
+// simplifyBinary simplifies Xor and Equiv. same is the value of the operator
+// if both operands are equal, value computes it for constant operands.
+func simplifyBinary(lhs ast.Node, rhs ast.Node, node ast.Node, same bool,
+	value func(bool, bool) bool) ast.Node {
+	if lhs == rhs {
+		return ast.Const{Value: same}
+	}
+	var left, isLeftConst = lhs.(ast.Const)
+	var right, isRightConst = rhs.(ast.Const)
+	switch {
+	case isLeftConst && isRightConst:
+		return ast.Const{Value: value(left.Value, right.Value)}
+	case isLeftConst:
+		return constOperand(value(left.Value, true), rhs)
+	case isRightConst:
+		return constOperand(value(right.Value, true), lhs)
+	}
+	return node
+}
+
+// constOperand returns the result of Xor or Equiv with a constant operand:
+// the other operand if identity is true and its negation otherwise.
+func constOperand(identity bool, operand ast.Node) ast.Node {
+	if identity {
+		return operand
+	}
+	return simplify(ast.Not{Ex: operand})
+}
+
+// simplifyChain simplifies a chain of And nodes if and is true or a chain of
+// Or nodes otherwise.
+func simplifyChain(node ast.Node, and bool) ast.Node {
+	var operands []ast.Node
+	for _, operand := range flatten(node, and) {
+		if constant, isConst := operand.(ast.Const); isConst {
+			if constant.Value != and {
+				// false annihilates And, true annihilates Or.
+				return constant
+			}
+			continue
+		}
+		if contains(operands, operand) {
+			continue
+		}
+		if contains(operands, complement(operand)) {
+			return ast.Const{Value: !and}
+		}
+		operands = append(operands, operand)
+	}
+	operands = absorb(operands, and)
+	if len(operands) == 0 {
+		return ast.Const{Value: and}
+	}
+	var result = operands[0]
+	for _, operand := range operands[1:] {
+		if and {
+			result = ast.And{LHS: result, RHS: operand}
+		} else {
+			result = ast.Or{LHS: result, RHS: operand}
+		}
+	}
+	return result
+}
+
+// absorb removes the operands of an And chain that are Or chains containing
+// another operand, e.g. "a & (a | b)" becomes "a". For Or chains it's the
+// other way round. Of two chains with the same operands, e.g. "a | b" and
+// "b | a", the first one is kept.
+func absorb(operands []ast.Node, and bool) []ast.Node {
+	var kept []ast.Node
+	for index, operand := range operands {
+		var inner = flatten(operand, !and)
+		var absorbed = false
+		for other, absorbing := range operands {
+			var absorbingInner = flatten(absorbing, !and)
+			if other != index && containsAll(inner, absorbingInner) &&
+				(len(absorbingInner) < len(inner) || other < index) {
+				absorbed = true
+				break
+			}
+		}
+		if !absorbed {
+			kept = append(kept, operand)
+		}
+	}
+	return kept
+}
+
+// flatten returns the operands of a chain of And nodes if and is true or of a
+// chain of Or nodes otherwise, e.g. a, b and c for "(a & b) & c". Any other
+// node is a chain of one.
+func flatten(node ast.Node, and bool) []ast.Node {
+	var lhs, rhs ast.Node
+	switch n := node.(type) {
+	case ast.And:
+		if !and {
+			return []ast.Node{node}
+		}
+		lhs, rhs = n.LHS, n.RHS
+	case ast.Or:
+		if and {
+			return []ast.Node{node}
+		}
+		lhs, rhs = n.LHS, n.RHS
+	default:
+		return []ast.Node{node}
+	}
+	return append(flatten(lhs, and), flatten(rhs, and)...)
+}
+
+// complement returns the negation of the node without double negation.
+func complement(node ast.Node) ast.Node {
+	if not, isNot := node.(ast.Not); isNot {
+		return not.Ex
+	}
+	return ast.Not{Ex: node}
+}
+
+// contains returns true if one of the nodes is equal to the node.
+func contains(nodes []ast.Node, node ast.Node) bool {
+	for _, other := range nodes {
+		if other == node {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAll returns true if nodes contains all the others.
+func containsAll(nodes []ast.Node, others []ast.Node) bool {
+	for _, other := range others {
+		if !contains(nodes, other) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/internal/asttest"
+)
+
+// testEquivalent checks that both ASTs have the same value for all
+// combinations of their variables.
+func testEquivalent(t *testing.T, name string, node ast.Node, result ast.Node) {
+	for _, vars := range asttest.Assignments(ast.Vars(ast.And{LHS: node, RHS: result})) {
+		if node.Eval(vars) != result.Eval(vars) {
+			t.Errorf("%s of %v isn't equivalent! Got %v, which differs for %v !",
+				name, node, result, vars)
+			return
+		}
+	}
+}
+
+// testTransform checks that the transformation turns text into expected.
+func testTransform(t *testing.T, name string, transform func(ast.Node) ast.Node,
+	text string, expected string) {
+	var node = boolparser.MustParse(text)
+	var result = transform(node)
+	if result != boolparser.MustParse(expected) {
+		t.Errorf("%s on input \"%v\" failed! Expected %v but got wrong result %v !",
+			name, text, boolparser.MustParse(expected), result)
+	}
+	testEquivalent(t, name, node, result)
+}
+
+// withLimit turns CNF and DNF into transformations with a generous limit.
+func withLimit(normalForm func(ast.Node, int) (ast.Node, error)) func(ast.Node) ast.Node {
+	return func(node ast.Node) ast.Node {
+		var result, err = normalForm(node, 1000)
+		if err != nil {
+			panic(err)
+		}
+		return result
+	}
+}
+
+func TestNNF(t *testing.T) {
+	testTransform(t, "NNF", NNF, "!!a", "a")
+	testTransform(t, "NNF", NNF, "!(a & !b)", "!a | b")
+	testTransform(t, "NNF", NNF, "!(a | b) & !true", "(!a & !b) & false")
+	testTransform(t, "NNF", NNF, "!(a -> b)", "a & !b")
+	testTransform(t, "NNF", NNF, "a ^ b", "(a & !b) | (!a & b)")
+	testTransform(t, "NNF", NNF, "!(a <-> !b)", "(a & b) | (!a & !b)")
+	testEquivalent(t, "NNF", boolparser.MustParse("!((a ^ b) <-> (c -> !(d & a)))"),
+		NNF(boolparser.MustParse("!((a ^ b) <-> (c -> !(d & a)))")))
+}
+
+func TestSimplify(t *testing.T) {
+	testTransform(t, "Simplify", Simplify, "!!a", "a")
+	testTransform(t, "Simplify", Simplify, "!!!a", "!a")
+	testTransform(t, "Simplify", Simplify, "a & a", "a")
+	testTransform(t, "Simplify", Simplify, "a | (a & b)", "a")
+	testTransform(t, "Simplify", Simplify, "(b & a) | a", "a")
+	testTransform(t, "Simplify", Simplify, "a & (b | a | c)", "a")
+	testTransform(t, "Simplify", Simplify, "(a | b) & (b | a)", "a | b")
+	testTransform(t, "Simplify", Simplify, "a & b & !a", "false")
+	testTransform(t, "Simplify", Simplify, "a | c | !!!a", "true")
+	testTransform(t, "Simplify", Simplify, "(a & true) | false | b", "a | b")
+	testTransform(t, "Simplify", Simplify, "true ^ a", "!a")
+	testTransform(t, "Simplify", Simplify, "a <-> false", "!a")
+	testTransform(t, "Simplify", Simplify, "(a -> false) | (b -> b)", "true")
+	testTransform(t, "Simplify", Simplify, "(!!(x & y) -> (x & y)) & !(z | z)", "!z")
+	testTransform(t, "Simplify", Simplify, "a -> b ^ c", "a -> b ^ c")
+
+	var spanned, _ = boolparser.ParseWithSpans("!!(a)")
+	if result := Simplify(spanned); result != (ast.Val{Name: "a"}) {
+		t.Errorf("Simplify must drop spans! Expected 'a' but got %v !", result)
+	}
+}
+
+func TestCNFAndDNF(t *testing.T) {
+	testTransform(t, "CNF", withLimit(CNF), "(a & b) | c", "(a | c) & (b | c)")
+	testTransform(t, "CNF", withLimit(CNF), "(a | b) & a", "a")
+	testTransform(t, "CNF", withLimit(CNF), "a -> b", "!a | b")
+	testTransform(t, "CNF", withLimit(CNF), "a | !a", "true")
+	testTransform(t, "CNF", withLimit(CNF), "a & !a", "a & !a")
+	testTransform(t, "DNF", withLimit(DNF), "(a | b) & c", "(a & c) | (b & c)")
+	testTransform(t, "DNF", withLimit(DNF), "a | (a & b)", "a")
+	testTransform(t, "DNF", withLimit(DNF), "a ^ b", "(a & !b) | (!a & b)")
+	testTransform(t, "DNF", withLimit(DNF), "a & !a", "false")
+	testTransform(t, "DNF", withLimit(DNF), "!(a & !a)", "!a | a")
+	testTransform(t, "DNF", withLimit(DNF), "!(a | !a)", "false")
+
+	var text = "!((a ^ b) <-> (c -> !(d & a))) | e"
+	testEquivalent(t, "CNF", boolparser.MustParse(text), withLimit(CNF)(boolparser.MustParse(text)))
+	testEquivalent(t, "DNF", boolparser.MustParse(text), withLimit(DNF)(boolparser.MustParse(text)))
+
+	// The DNF of (a0 | b0) & (a1 | b1) & ... has 2^n clauses.
+	var large ast.Node = ast.Or{LHS: ast.Val{Name: "a0"}, RHS: ast.Val{Name: "b0"}}
+	for index := 1; index < 12; index++ {
+		large = ast.And{LHS: large, RHS: ast.Or{
+			LHS: ast.Val{Name: fmt.Sprint("a", index)},
+			RHS: ast.Val{Name: fmt.Sprint("b", index)},
+		}}
+	}
+	if _, err := DNF(large, 1000); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("DNF must fail with ErrTooLarge but got %v !", err)
+	}
+	if result, err := CNF(large, 1000); err != nil || result != large {
+		t.Errorf("CNF of %v failed! Expected the same expression but got %v with error %v !",
+			large, result, err)
+	}
+
+	for _, test := range []struct {
+		text     string
+		limit    int
+		expected string
+	}{
+		{"true", 0, "true"},
+		{"a", 0, ""},
+		{"a | b", 0, ""},
+		{"a | b", 1, "a | b"},
+		{"(a & b) | c", 1, ""},
+		{"(a & b) | (a & c)", 2, "a & (b | c)"},
+	} {
+		var result, err = CNF(boolparser.MustParse(test.text), test.limit)
+		if test.expected == "" && !errors.Is(err, ErrTooLarge) {
+			t.Errorf("CNF on input \"%v\" with limit %d must fail with ErrTooLarge but got %v !", test.text, test.limit, err)
+		} else if test.expected != "" && (err != nil || result != boolparser.MustParse(test.expected)) {
+			t.Errorf("CNF on input \"%v\" with limit %d failed! Expected %v but got wrong result %v (%v) !",
+				test.text, test.limit, test.expected, result, err)
+		}
+	}
+}
+
+// foreign is a node of another package.
+type foreign struct{}
+
+func (foreign) Eval(vars map[string]bool) bool { return true }
+
+func TestNormalFormOfForeignNode(t *testing.T) {
+	var node = ast.Not{Ex: foreign{}}
+	if result := NNF(node); result != node {
+		t.Errorf("NNF on input \"%v\" failed! Expected %v but got wrong result %v !", node, node, result)
+	}
+	if _, err := DNF(ast.And{LHS: ast.Val{Name: "a"}, RHS: node}, 1000); err == nil || errors.Is(err, ErrTooLarge) {
+		t.Errorf("DNF on input \"%v\" must fail but got %v !", node, err)
+	}
+}
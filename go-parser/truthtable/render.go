@@ -0,0 +1,153 @@
+package truthtable
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/format"
+)
+
+// Format is the output format of Render.
+type Format int
+
+const (
+
+	// Text renders the table as plain text with aligned columns that are
+	// separated by two spaces.
+	Text Format = iota
+
+	// Markdown renders the table as a GitHub flavored Markdown table.
+	Markdown
+
+	// CSV renders the table as comma-separated values with a header line.
+	CSV
+
+	// JSON renders the table as a JSON object with the variables, the
+	// expressions and the rows.
+	JSON
+)
+
+// formatNames are the names of the formats for ParseFormat.
+var formatNames = map[string]Format{"text": Text, "markdown": Markdown, "csv": CSV, "json": JSON}
+
+// ParseFormat returns the Format with the name "text", "markdown", "csv" or
+// "json".
+func ParseFormat(name string) (Format, error) {
+	var format, found = formatNames[strings.ToLower(name)]
+	if !found {
+		return Text, fmt.Errorf("unknown format %q, use text, markdown, csv or json", name)
+	}
+	return format, nil
+}
+
+// Render writes the table in the format to the writer. The text formats show
+// the values as 0 and 1.
+func (table *Table) Render(writer io.Writer, format Format) error {
+	switch format {
+	case Markdown:
+		return table.renderMarkdown(writer)
+	case CSV:
+		return table.renderCSV(writer)
+	case JSON:
+		return table.renderJSON(writer)
+	}
+	return table.renderText(writer)
+}
+
+// header returns the headings of the columns.
+func (table *Table) header() []string {
+	var header = append([]string{}, table.Vars...)
+	for _, expression := range table.Expressions {
+		header = append(header, format.String(expression))
+	}
+	return header
+}
+
+// digit returns the value as 0 or 1.
+func digit(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// renderText renders the table as Text.
+func (table *Table) renderText(writer io.Writer) error {
+	var header = table.header()
+	var lines = make([]string, 0, len(table.Rows)+2)
+	var cells = make([]string, len(header))
+	var rules = make([]string, len(header))
+	for column, heading := range header {
+		cells[column] = heading
+		rules[column] = strings.Repeat("-", len(heading))
+	}
+	lines = append(lines, strings.Join(cells, "  "), strings.Join(rules, "  "))
+	for _, row := range table.Rows {
+		for column, value := range row {
+			cells[column] = digit(value) + strings.Repeat(" ", len(header[column])-1)
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+	_, err := fmt.Fprintln(writer, strings.Join(lines, "\n"))
+	return err
+}
+
+// renderMarkdown renders the table as Markdown.
+func (table *Table) renderMarkdown(writer io.Writer) error {
+	var header = table.header()
+	var rules = make([]string, len(header))
+	for column, heading := range header {
+		header[column] = strings.ReplaceAll(heading, "|", "\\|")
+		rules[column] = "---"
+	}
+	var lines = []string{
+		"| " + strings.Join(header, " | ") + " |",
+		"| " + strings.Join(rules, " | ") + " |",
+	}
+	var cells = make([]string, len(header))
+	for _, row := range table.Rows {
+		for column, value := range row {
+			cells[column] = digit(value)
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+	}
+	_, err := fmt.Fprintln(writer, strings.Join(lines, "\n"))
+	return err
+}
+
+// renderCSV renders the table as CSV.
+func (table *Table) renderCSV(writer io.Writer) error {
+	var csvWriter = csv.NewWriter(writer)
+	if err := csvWriter.Write(table.header()); err != nil {
+		return err
+	}
+	for _, row := range table.Rows {
+		var cells = make([]string, len(row))
+		for column, value := range row {
+			cells[column] = digit(value)
+		}
+		if err := csvWriter.Write(cells); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// renderJSON renders the table as JSON.
+func (table *Table) renderJSON(writer io.Writer) error {
+	var expressions = make([]string, len(table.Expressions))
+	for index, expression := range table.Expressions {
+		expressions[index] = format.String(expression)
+	}
+	var encoder = json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(struct {
+		Vars        []string `json:"vars"`
+		Expressions []string `json:"expressions"`
+		Rows        [][]bool `json:"rows"`
+	}{table.Vars, expressions, table.Rows})
+}
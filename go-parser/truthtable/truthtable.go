@@ -0,0 +1,105 @@
+package truthtable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+)
+
+// DefaultMaxVars is the maximum number of variables of Options that don't set
+// MaxVars. Its table has 65536 rows.
+const DefaultMaxVars = 16
+
+// MaxVarsLimit is the largest MaxVars that New accepts. Its table has 2^30
+// rows, which is as many as an int can count on every platform.
+const MaxVarsLimit = 30
+
+// ErrTooManyVars is returned by New if the expression has more variables than
+// allowed.
+var ErrTooManyVars = errors.New("too many variables for a truth table")
+
+// Options configure the Table created by New.
+type Options struct {
+
+	// MaxVars is the maximum number of variables. A table for n variables has
+	// 2^n rows. If MaxVars is 0 then DefaultMaxVars applies, it mustn't exceed
+	// MaxVarsLimit.
+	MaxVars int
+
+	// SubExpressions adds a column for every sub-expression that isn't a
+	// variable. The sub-expressions come before the expressions that contain
+	// them, every sub-expression appears once.
+	SubExpressions bool
+}
+
+// Table is the truth table of an expression.
+type Table struct {
+
+	// Vars are the names of the variables in sorted order.
+	Vars []string
+
+	// Expressions are the expressions of the result columns. The last one is
+	// the whole expression.
+	Expressions []ast.Node
+
+	// Rows hold the values of the Vars followed by the values of the
+	// Expressions. The first row sets all variables to false, the last row sets
+	// all of them to true. The last variable changes from row to row.
+	Rows [][]bool
+}
+
+// New creates the truth table of the expression, i. e. it evaluates the
+// expression for all the combinations of the values of its variables. It fails
+// with ErrTooManyVars if there are more variables than allowed by the options
+// or if the options allow more than MaxVarsLimit.
+func New(node ast.Node, options Options) (*Table, error) {
+	node = ast.StripSpans(node)
+	var maxVars = options.MaxVars
+	if maxVars == 0 {
+		maxVars = DefaultMaxVars
+	}
+	if maxVars > MaxVarsLimit {
+		return nil, fmt.Errorf("%w: MaxVars %d exceeds the limit %d",
+			ErrTooManyVars, maxVars, MaxVarsLimit)
+	}
+	var table = &Table{Vars: ast.Vars(node), Expressions: []ast.Node{node}}
+	if len(table.Vars) > maxVars {
+		return nil, fmt.Errorf("%w: %d variables, at most %d allowed",
+			ErrTooManyVars, len(table.Vars), maxVars)
+	}
+	if options.SubExpressions {
+		table.Expressions = subExpressions(node, nil)
+	}
+	for combination := 0; combination < 1<<len(table.Vars); combination++ {
+		var row = make([]bool, 0, len(table.Vars)+len(table.Expressions))
+		var vars = make(map[string]bool)
+		for index, name := range table.Vars {
+			var value = combination&(1<<(len(table.Vars)-1-index)) != 0
+			vars[name] = value
+			row = append(row, value)
+		}
+		for _, expression := range table.Expressions {
+			row = append(row, expression.Eval(vars))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// subExpressions appends the sub-expressions of the node that aren't
+// variables and aren't in found yet to found, the operands before the node.
+func subExpressions(node ast.Node, found []ast.Node) []ast.Node {
+	if _, isVal := node.(ast.Val); isVal {
+		return found
+	}
+	for _, operand := range ast.Operands(node) {
+		found = subExpressions(operand, found)
+	}
+	for _, other := range found {
+		if other == node {
+			return found
+		}
+	}
+	return append(found, node)
+}
@@ -0,0 +1,74 @@
+package truthtable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/ast"
+	"github.com/m-voit/concepts-of-programming-languages/go-parser/boolparser"
+)
+
+// testRender checks the table of text rendered in the format.
+func testRender(t *testing.T, text string, options Options, format Format, expected string) {
+	var table, err = New(boolparser.MustParse(text), options)
+	if err != nil {
+		t.Fatalf("New on input \"%v\" failed with error %v !", text, err)
+	}
+	var builder strings.Builder
+	if err = table.Render(&builder, format); err != nil || builder.String() != expected {
+		t.Errorf("Render on input \"%v\" failed! Expected\n%v\nbut got wrong result\n%v\nwith error %v !",
+			text, expected, builder.String(), err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	var table, err = New(boolparser.MustParse("b & !(a | b)"), Options{SubExpressions: true})
+	var expected = "a | b, !(a | b), b & !(a | b)"
+	if err != nil || strings.Join(table.header()[2:], ", ") != expected {
+		t.Errorf("New on input \"b & !(a | b)\" failed! Expected sub-expressions %v but got %v !",
+			expected, table.Expressions)
+	}
+	if len(table.Rows) != 4 || table.Rows[3][0] != true || table.Rows[1][1] != true || table.Rows[2][1] != false {
+		t.Errorf("New on input \"b & !(a | b)\" failed! Rows in wrong order: %v !", table.Rows)
+	}
+
+	_, err = New(boolparser.MustParse("a & b & c"), Options{MaxVars: 2})
+	if !errors.Is(err, ErrTooManyVars) {
+		t.Errorf("New must fail with ErrTooManyVars but got %v !", err)
+	}
+	_, err = New(boolparser.MustParse("a"), Options{MaxVars: 64})
+	if !errors.Is(err, ErrTooManyVars) {
+		t.Errorf("New must reject MaxVars above MaxVarsLimit but got %v !", err)
+	}
+	table, err = New(ast.Const{Value: true}, Options{})
+	if err != nil || len(table.Rows) != 1 || !table.Rows[0][0] {
+		t.Errorf("New on input \"true\" failed! Expected one row but got %v with error %v !", table.Rows, err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	testRender(t, "a -> b", Options{}, Text,
+		"a  b  a -> b\n"+
+			"-  -  ------\n"+
+			"0  0  1\n"+
+			"0  1  1\n"+
+			"1  0  0\n"+
+			"1  1  1\n")
+	testRender(t, "!a | a", Options{SubExpressions: true}, Markdown,
+		"| a | !a | !a \\| a |\n"+
+			"| --- | --- | --- |\n"+
+			"| 0 | 1 | 1 |\n"+
+			"| 1 | 0 | 1 |\n")
+	testRender(t, "a ^ b", Options{}, CSV,
+		"a,b,a ^ b\n0,0,0\n0,1,1\n1,0,1\n1,1,0\n")
+	testRender(t, "!a", Options{}, JSON,
+		`{"vars":["a"],"expressions":["!a"],"rows":[[false,true],[true,false]]}`+"\n")
+
+	if _, err := ParseFormat("html"); err == nil {
+		t.Errorf("ParseFormat must fail on unknown formats!")
+	}
+	if format, err := ParseFormat("Markdown"); err != nil || format != Markdown {
+		t.Errorf("ParseFormat on input \"Markdown\" failed! Got %v with error %v !", format, err)
+	}
+}